@@ -3,8 +3,11 @@ package di
 import (
 	"context"
 	"errors"
-	"fmt"
 	"log/slog"
+	"os"
+	"slices"
+	"sync"
+	"sync/atomic"
 	"time"
 )
 
@@ -21,17 +24,25 @@ type Servicer interface {
 type App struct {
 	container *Container
 
-	logger       *slog.Logger
+	observers    []Observer
 	startTimeout time.Duration
 	stopTimeout  time.Duration
+
+	maxParallelism int
+
+	signals       []os.Signal
+	reloadSignals []os.Signal
+	drainDelay    time.Duration
+	exit          func(code int)
+
+	state atomic.Int32
 }
 
 type AppOpt func(*App)
 
+// WithLogger logs the events of the App and its container with NewSlogObserver.
 func WithLogger(logger *slog.Logger) AppOpt {
-	return func(app *App) {
-		app.logger = logger
-	}
+	return WithObserver(NewSlogObserver(logger))
 }
 
 func WithStartTimeout(timeout time.Duration) AppOpt {
@@ -46,138 +57,307 @@ func WithStopTimeout(timeout time.Duration) AppOpt {
 	}
 }
 
+// WithMaxParallelism limits how many services of a wave are started or stopped at the same
+// time. Zero, the default, means no limit; 1 starts services one by one in dependency order.
+func WithMaxParallelism(n int) AppOpt {
+	return func(app *App) {
+		app.maxParallelism = n
+	}
+}
+
 func NewApp(container *Container, opts ...AppOpt) *App {
 	app := &App{
-		container:    container,
-		startTimeout: DefaultStartTimeout,
-		stopTimeout:  DefaultStopTimeout,
+		container:     container,
+		startTimeout:  DefaultStartTimeout,
+		stopTimeout:   DefaultStopTimeout,
+		signals:       defaultSignals,
+		reloadSignals: defaultReloadSignals,
+		exit:          os.Exit,
 	}
 
 	for _, opt := range opts {
 		opt(app)
 	}
 
+	for _, observer := range app.observers {
+		container.AddObserver(observer)
+	}
+
 	return app
 }
 
+// Run starts the application, runs the Runner instances until ctx is done or one of them
+// fails, and stops the application. The first runner failure is returned together with any
+// stop error. If Start fails, the services that did start have already been stopped and
+// the error is returned.
 func (app *App) Run(ctx context.Context) error {
-	if err := app.runStart(ctx); err != nil {
-		_ = app.runStop(ctx)
-
+	if err := app.Start(ctx); err != nil {
 		return err
 	}
 
-	<-ctx.Done()
+	runErr := app.runRunners(ctx)
 
-	return app.runStop(context.Background())
+	reason := AppStopping{Reason: ShutdownContextDone}
+	var sig signalCause
+	switch {
+	case runErr != nil:
+		reason.Reason = ShutdownRunnerFailed
+	case errors.As(context.Cause(ctx), &sig):
+		reason = AppStopping{Reason: ShutdownSignal, Signal: sig.signal}
+	}
+
+	return errors.Join(runErr, app.stop(context.Background(), reason))
 }
 
+// Start starts the Servicer instances of the container in dependency order. Services are
+// started in waves: a service starts once every service it depends on, directly or through
+// other instances, has started, and the services of a wave start concurrently. The first
+// error cancels the rest of its wave and no further waves are started. The services that
+// started successfully are then stopped in reverse order, and the start error is returned
+// joined with any errors of that rollback. A service whose Start ignores the cancellation and
+// succeeds later is stopped as soon as its Start returns.
 func (app *App) Start(ctx context.Context) error {
-	app.logInfo("Starting...")
-
-	var services []Servicer
-	for _, instance := range app.container.instancesList {
-		service, ok := instance.(Servicer)
-		if ok {
-			services = append(services, service)
-		}
+	startCtx := ctx
+	if app.startTimeout > 0 {
+		var cancel context.CancelFunc
+		startCtx, cancel = context.WithTimeout(ctx, app.startTimeout)
+		defer cancel()
 	}
 
-	var err error
-	for _, service := range services {
-		if err = withTimeout(ctx, service.Start); err != nil {
+	app.state.Store(int32(StateStarting))
+	app.emit(AppStarting{})
+	began := time.Now()
+
+	var (
+		started [][]*service
+		err     error
+	)
+
+	for _, wave := range app.container.serviceWaves() {
+		var done []*service
+		done, err = app.runWave(startCtx, wave, startOp, true)
+		started = append(started, done)
+
+		if err != nil {
 			break
 		}
 	}
 
-	switch {
-	case errors.Is(err, context.DeadlineExceeded):
-		app.logError("Start timed out.")
+	app.emit(AppStarted{Duration: time.Since(began), Err: err})
 
-		return err
+	if err == nil {
+		app.state.Store(int32(StateRunning))
+
+		return nil
+	}
 
-	case err != nil:
-		app.logError("Failed to start: %v", err)
+	app.state.Store(int32(StateStopping))
+	defer app.state.Store(int32(StateStopped))
 
-		return err
+	app.emit(AppStopping{Reason: ShutdownStartFailed})
+	began = time.Now()
+
+	stopCtx := context.WithoutCancel(ctx)
+	if app.stopTimeout > 0 {
+		var cancel context.CancelFunc
+		stopCtx, cancel = context.WithTimeout(stopCtx, app.stopTimeout)
+		defer cancel()
 	}
 
-	app.logInfo("Started.")
+	rollbackErr := app.stopWaves(stopCtx, started)
+	app.emit(AppStopped{Duration: time.Since(began), Err: rollbackErr})
 
-	return nil
+	if rollbackErr != nil {
+		return errors.Join(err, rollbackErr)
+	}
+
+	return err
 }
 
+// Stop stops the Servicer instances in the reverse order of Start, wave by wave.
+// Services of a wave are stopped concurrently and all errors are returned joined, except for
+// stops that timed out, which are only reported to observers.
 func (app *App) Stop(ctx context.Context) error {
-	app.logInfo("Stopping...")
-
-	var services []Servicer
-	for i := len(app.container.instancesList) - 1; i >= 0; i-- {
-		instance := app.container.instancesList[i]
-		service, ok := instance.(Servicer)
-		if ok {
-			services = append(services, service)
-		}
+	return app.stop(ctx, AppStopping{Reason: ShutdownStopCalled})
+}
+
+func (app *App) stop(ctx context.Context, reason AppStopping) error {
+	if app.stopTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, app.stopTimeout)
+		defer cancel()
 	}
 
-	var err error
-	for _, service := range services {
-		if stopErr := withTimeout(ctx, service.Stop); stopErr != nil {
-			if err == nil {
-				err = stopErr
+	app.state.Store(int32(StateStopping))
+	defer app.state.Store(int32(StateStopped))
+
+	app.emit(reason)
+	began := time.Now()
+
+	err := app.stopWaves(ctx, app.container.serviceWaves())
+	app.emit(AppStopped{Duration: time.Since(began), Err: err})
+
+	return withoutTimeouts(err)
+}
+
+// withoutTimeouts drops the timed out stops from a joined stop error. Timeouts are reported
+// with the Timeout event; the other errors are kept.
+func withoutTimeouts(err error) error {
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		var errs []error
+		for _, e := range joined.Unwrap() {
+			if e = withoutTimeouts(e); e != nil {
+				errs = append(errs, e)
 			}
 		}
-	}
 
-	switch {
-	case errors.Is(err, context.DeadlineExceeded):
-		app.logError("Stop timed out.")
+		return errors.Join(errs...)
+	}
 
+	if errors.Is(err, context.DeadlineExceeded) {
 		return nil
-	case err != nil:
-		app.logError("Failed to stop cleanly: %v", err)
-
-		return err
 	}
 
-	app.logInfo("Stopped.")
+	return err
+}
+
+// stopWaves stops the services of the waves in reverse order, last wave first, and joins the errors.
+func (app *App) stopWaves(ctx context.Context, waves [][]*service) error {
+	var errs []error
+	for i := len(waves) - 1; i >= 0; i-- {
+		wave := slices.Clone(waves[i])
+		slices.Reverse(wave)
 
-	return nil
+		if _, err := app.runWave(ctx, wave, stopOp, false); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	return errors.Join(errs...)
 }
 
-func (app *App) runStart(ctx context.Context) error {
-	if app.startTimeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, app.startTimeout)
-		defer cancel()
+// runWave runs op for the services of a wave concurrently, at most maxParallelism at a time,
+// and returns the services it succeeded for. Errors are wrapped in ServiceError. With failFast
+// the first error cancels the calls of the other services and is returned alone, otherwise
+// all errors are joined. A failFast call abandoned on cancellation or timeout that still
+// succeeds later is rolled back with stopLate.
+func (app *App) runWave(ctx context.Context, wave []*service, op serviceOp, failFast bool) ([]*service, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var sem chan struct{}
+	if app.maxParallelism > 0 {
+		sem = make(chan struct{}, app.maxParallelism)
 	}
 
-	return app.Start(ctx)
+	var (
+		wg       sync.WaitGroup
+		once     sync.Once
+		firstErr error
+	)
+
+	errs := make([]error, len(wave))
+	succeeded := make([]bool, len(wave))
+	for i, svc := range wave {
+		if sem != nil {
+			sem <- struct{}{}
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if sem != nil {
+				defer func() { <-sem }()
+			}
+
+			if failFast && ctx.Err() != nil {
+				return
+			}
+
+			var late func(error)
+			if failFast {
+				late = func(err error) {
+					if err == nil {
+						app.stopLate(svc)
+					}
+				}
+			}
+
+			err := app.runService(ctx, svc, op, late)
+			if err == nil {
+				succeeded[i] = true
+
+				return
+			}
+
+			errs[i] = err
+			if failFast {
+				once.Do(func() {
+					firstErr = err
+					cancel()
+				})
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	var done []*service
+	for i, svc := range wave {
+		if succeeded[i] {
+			done = append(done, svc)
+		}
+	}
+
+	switch {
+	case !failFast:
+		return done, errors.Join(errs...)
+	case firstErr == nil:
+		return done, ctx.Err()
+	default:
+		return done, firstErr
+	}
 }
 
-func (app *App) runStop(ctx context.Context) error {
+// stopLate stops a service whose start returned successfully after it was abandoned,
+// once the rest of the start has already been rolled back.
+func (app *App) stopLate(svc *service) {
+	ctx := context.Background()
 	if app.stopTimeout > 0 {
 		var cancel context.CancelFunc
 		ctx, cancel = context.WithTimeout(ctx, app.stopTimeout)
 		defer cancel()
 	}
 
-	return app.Stop(ctx)
+	// The outcome is reported to observers with ServiceStopped.
+	_ = app.runService(ctx, svc, stopOp, nil)
 }
 
-func (app *App) logInfo(msg string, args ...any) {
-	if app.logger == nil {
-		return
+// runService runs op for a single service and reports its outcome with the time it took.
+// late receives the result of a call abandoned on ctx, see callWithTimeout.
+func (app *App) runService(ctx context.Context, svc *service, op serviceOp, late func(error)) error {
+	fn := op.fn(svc)
+	if fn == nil {
+		return nil
 	}
 
-	app.logger.Info(fmt.Sprintf(msg, args...))
-}
+	frame := svc.provider.frame()
+	app.emit(op.begin(frame))
 
-func (app *App) logError(msg string, args ...any) {
-	if app.logger == nil {
-		return
+	started := time.Now()
+	err := callWithTimeout(ctx, op.timeout(svc.provider), fn, late)
+	if errors.Is(err, context.DeadlineExceeded) {
+		app.emit(Timeout{Op: op.verb, Service: frame})
 	}
 
-	app.logger.Error(fmt.Sprintf(msg, args...))
+	app.emit(op.end(frame, time.Since(started), err))
+
+	if err != nil {
+		return &ServiceError{Op: op.verb, Service: frame, Err: err}
+	}
+
+	return nil
 }
 
 func withTimeout(ctx context.Context, fn func(context.Context) error) error {
@@ -85,7 +85,9 @@ func TestApp_Start(t *testing.T) {
 			expectedErr:  context.DeadlineExceeded,
 			setupMocks: func(mock1 *MockAppService1, mock2 *MockAppService2) {
 				mock1.On("Start", mock.Anything).After(time.Second).Return(nil)
-				mock2.On("Start", mock.Anything).Return(nil)
+				mock1.On("Stop", mock.Anything).Return(nil).Maybe() // stopped once the late start returns
+				mock2.On("Start", mock.Anything).Return(nil).Maybe()
+				mock2.On("Stop", mock.Anything).Return(nil).Maybe()
 			},
 		},
 		{
@@ -95,6 +97,8 @@ func TestApp_Start(t *testing.T) {
 			expectedErr:  errStart,
 			setupMocks: func(mock1 *MockAppService1, mock2 *MockAppService2) {
 				mock1.On("Start", mock.Anything).Return(errStart)
+				mock2.On("Start", mock.Anything).Return(nil).Maybe()
+				mock2.On("Stop", mock.Anything).Return(nil).Maybe()
 			},
 		},
 	}
@@ -174,6 +178,16 @@ func TestApp_Stop(t *testing.T) {
 				mock2.On("Stop", mock.Anything).Return(nil)
 			},
 		},
+		{
+			name:        "stop timeout and error",
+			stopTimeout: 100 * time.Millisecond,
+			contextTime: time.Second,
+			expectedErr: errStop,
+			setupMocks: func(mock1 *MockAppService1, mock2 *MockAppService2) {
+				mock1.On("Stop", mock.Anything).After(time.Second).Return(nil)
+				mock2.On("Stop", mock.Anything).Return(errStop)
+			},
+		},
 		{
 			name:        "stop error",
 			stopTimeout: 0,
@@ -265,8 +279,9 @@ func TestApp_Run(t *testing.T) {
 			expectedErr:  context.DeadlineExceeded,
 			setupMocks: func(mock1 *MockAppService1, mock2 *MockAppService2) {
 				mock1.On("Start", mock.Anything).After(time.Second).Return(nil)
-				mock1.On("Stop", mock.Anything).Return(nil)
-				mock2.On("Stop", mock.Anything).Return(nil)
+				mock1.On("Stop", mock.Anything).Return(nil).Maybe() // stopped once the late start returns
+				mock2.On("Start", mock.Anything).Return(nil).Maybe()
+				mock2.On("Stop", mock.Anything).Return(nil).Maybe()
 			},
 		},
 		{
@@ -277,8 +292,8 @@ func TestApp_Run(t *testing.T) {
 			expectedErr:  errStart,
 			setupMocks: func(mock1 *MockAppService1, mock2 *MockAppService2) {
 				mock1.On("Start", mock.Anything).Return(errStart)
-				mock1.On("Stop", mock.Anything).Return(nil)
-				mock2.On("Stop", mock.Anything).Return(nil)
+				mock2.On("Start", mock.Anything).Return(nil).Maybe()
+				mock2.On("Stop", mock.Anything).Return(nil).Maybe()
 			},
 		},
 		{
@@ -0,0 +1,459 @@
+package di
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"reflect"
+	"runtime"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/BurntSushi/toml"
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigOption configures how ProvideConfig and LoadConfig populate a config struct.
+type ConfigOption func(*configLoader)
+
+// ConfigFile reads values from a file. The format is chosen by extension: .json, .yaml,
+// .yml and .toml are supported out of the box, other formats can be added with ConfigDecoder.
+// Files are applied in the order they are given.
+func ConfigFile(path string) ConfigOption {
+	return func(l *configLoader) {
+		l.files = append(l.files, path)
+	}
+}
+
+// ConfigOptionalFile is like ConfigFile but a missing file is ignored.
+func ConfigOptionalFile(path string) ConfigOption {
+	return func(l *configLoader) {
+		l.files = append(l.files, path)
+		l.optionalFiles[path] = true
+	}
+}
+
+// ConfigDecoder registers a decoder for files with the given extension or replaces a built-in
+// one. The decoder must support decoding into map[string]any.
+func ConfigDecoder(ext string, decode func(data []byte, v any) error) ConfigOption {
+	return func(l *configLoader) {
+		l.decoders[ext] = decode
+	}
+}
+
+// ConfigEnvPrefix is prepended to the names of env tags, e.g. prefix "APP_" and
+// `env:"DB_HOST"` read APP_DB_HOST.
+func ConfigEnvPrefix(prefix string) ConfigOption {
+	return func(l *configLoader) {
+		l.envPrefix = prefix
+	}
+}
+
+// ConfigFlags parses command line arguments for fields with a flag tag, e.g. os.Args[1:].
+func ConfigFlags(args []string) ConfigOption {
+	return func(l *configLoader) {
+		l.flagArgs = args
+		l.useFlags = true
+	}
+}
+
+// ProvideConfig registers a provider of *T that populates T when it is first resolved.
+// Values are applied in order of precedence from lowest to highest: `default:"..."` tags,
+// files, environment variables from `env:"..."` tags and command line flags from
+// `flag:"..."` tags. File keys are taken from `config:"..."` tags or the field name,
+// case-insensitively, and nested structs map to nested objects. Fields tagged
+// `config:",required"` must be set by a file, env or flag; a default does not count.
+// If *T has a Validate() error method, it is called last.
+func ProvideConfig[T any](c *Container, opts ...ConfigOption) *Provider {
+	prvdr := newProvider(func() (*T, error) {
+		return LoadConfig[T](opts...)
+	})
+
+	prvdr.name = fmt.Sprintf("ProvideConfig(%v)", reflect.TypeFor[T]())
+	_, prvdr.file, prvdr.line, _ = runtime.Caller(1)
+
+	c.register(prvdr)
+
+	return prvdr
+}
+
+// LoadConfig populates a new T the same way ProvideConfig does.
+func LoadConfig[T any](opts ...ConfigOption) (*T, error) {
+	typ := reflect.TypeFor[T]()
+	if typ.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("config type %v must be a struct", typ)
+	}
+
+	l := &configLoader{
+		optionalFiles: make(map[string]bool),
+		decoders: map[string]func([]byte, any) error{
+			".json": json.Unmarshal,
+			".yaml": yaml.Unmarshal,
+			".yml":  yaml.Unmarshal,
+			".toml": toml.Unmarshal,
+		},
+	}
+
+	for _, opt := range opts {
+		opt(l)
+	}
+
+	cfg := new(T)
+	if err := l.load(reflect.ValueOf(cfg).Elem()); err != nil {
+		return nil, err
+	}
+
+	if v, ok := any(cfg).(interface{ Validate() error }); ok {
+		if err := v.Validate(); err != nil {
+			return nil, fmt.Errorf("invalid config %v: %w", typ, err)
+		}
+	}
+
+	return cfg, nil
+}
+
+type configLoader struct {
+	files         []string
+	optionalFiles map[string]bool
+	decoders      map[string]func([]byte, any) error
+	envPrefix     string
+	flagArgs      []string
+	useFlags      bool
+}
+
+// configField is a leaf field of a config struct.
+type configField struct {
+	path     string
+	keys     []string
+	value    reflect.Value
+	env      string
+	flag     string
+	def      string
+	hasDef   bool
+	required bool
+	set      bool
+}
+
+func (l *configLoader) load(cfg reflect.Value) error {
+	var fields []*configField
+	collectConfigFields(cfg, nil, "", &fields)
+
+	var errs []error
+	for _, field := range fields {
+		if field.hasDef {
+			if err := setConfigValue(field.value, field.def); err != nil {
+				errs = append(errs, fmt.Errorf("default of %s: %w", field.path, err))
+			}
+		}
+	}
+
+	for _, path := range l.files {
+		values, err := l.readFile(path)
+		if err != nil {
+			errs = append(errs, err)
+
+			continue
+		}
+
+		for _, field := range fields {
+			raw, ok := lookupConfigKey(values, field.keys)
+			if !ok {
+				continue
+			}
+
+			if err := assignConfigValue(field.value, raw); err != nil {
+				errs = append(errs, fmt.Errorf("%s in %s: %w", field.path, path, err))
+
+				continue
+			}
+
+			field.set = true
+		}
+	}
+
+	for _, field := range fields {
+		if field.env == "" {
+			continue
+		}
+
+		name := l.envPrefix + field.env
+		raw, ok := os.LookupEnv(name)
+		if !ok {
+			continue
+		}
+
+		if err := setConfigValue(field.value, raw); err != nil {
+			errs = append(errs, fmt.Errorf("%s from env %s: %w", field.path, name, err))
+
+			continue
+		}
+
+		field.set = true
+	}
+
+	if l.useFlags {
+		if err := l.parseFlags(fields); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	for _, field := range fields {
+		if field.required && !field.set {
+			errs = append(errs, fmt.Errorf("required config field %s is not set", field.path))
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (l *configLoader) readFile(path string) (map[string]any, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if l.optionalFiles[path] && errors.Is(err, os.ErrNotExist) {
+			return nil, nil
+		}
+
+		return nil, fmt.Errorf("read config file: %w", err)
+	}
+
+	ext := strings.ToLower(filepath.Ext(path))
+	decode, ok := l.decoders[ext]
+	if !ok {
+		return nil, fmt.Errorf("no config decoder for %q files, register one with ConfigDecoder", ext)
+	}
+
+	values := make(map[string]any)
+	if err := decode(data, &values); err != nil {
+		return nil, fmt.Errorf("decode config file %s: %w", path, err)
+	}
+
+	return values, nil
+}
+
+func (l *configLoader) parseFlags(fields []*configField) error {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+	fs.SetOutput(io.Discard)
+
+	values := make(map[string]*configFlag)
+	for _, field := range fields {
+		if field.flag == "" {
+			continue
+		}
+
+		if f := fs.Lookup(field.flag); f != nil {
+			return fmt.Errorf("flag -%s is used by both %s and %s", field.flag, f.Usage, field.path)
+		}
+
+		values[field.flag] = &configFlag{value: field.def, isBool: field.value.Kind() == reflect.Bool}
+		fs.Var(values[field.flag], field.flag, field.path)
+	}
+
+	if err := fs.Parse(l.flagArgs); err != nil {
+		return err
+	}
+
+	var errs []error
+	fs.Visit(func(f *flag.Flag) {
+		for _, field := range fields {
+			if field.flag != f.Name {
+				continue
+			}
+
+			if err := setConfigValue(field.value, values[f.Name].value); err != nil {
+				errs = append(errs, fmt.Errorf("%s from flag -%s: %w", field.path, f.Name, err))
+
+				continue
+			}
+
+			field.set = true
+		}
+	})
+
+	return errors.Join(errs...)
+}
+
+// configFlag holds the raw value of a flag. Flags of bool fields may omit the value.
+type configFlag struct {
+	value  string
+	isBool bool
+}
+
+func (f *configFlag) String() string { return f.value }
+
+func (f *configFlag) Set(value string) error {
+	f.value = value
+
+	return nil
+}
+
+func (f *configFlag) IsBoolFlag() bool { return f.isBool }
+
+func collectConfigFields(v reflect.Value, keys []string, path string, fields *[]*configField) {
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key, opts, _ := strings.Cut(field.Tag.Get("config"), ",")
+		if key == "-" {
+			continue
+		}
+
+		if key == "" {
+			key = field.Name
+		}
+
+		fieldKeys := append(append([]string(nil), keys...), key)
+		fieldPath := path + field.Name
+
+		if field.Type.Kind() == reflect.Struct && field.Type != reflect.TypeFor[time.Time]() {
+			collectConfigFields(v.Field(i), fieldKeys, fieldPath+".", fields)
+
+			continue
+		}
+
+		def, hasDef := field.Tag.Lookup("default")
+		*fields = append(*fields, &configField{
+			path:     fieldPath,
+			keys:     fieldKeys,
+			value:    v.Field(i),
+			env:      field.Tag.Get("env"),
+			flag:     field.Tag.Get("flag"),
+			def:      def,
+			hasDef:   hasDef,
+			required: strings.Contains(","+opts+",", ",required,"),
+		})
+	}
+}
+
+// lookupConfigKey walks nested maps by keys, matching keys case-insensitively.
+func lookupConfigKey(values map[string]any, keys []string) (any, bool) {
+	var current any = values
+	for _, key := range keys {
+		m, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = m[key]
+		if ok {
+			continue
+		}
+
+		found := false
+		for k, v := range m {
+			if strings.EqualFold(k, key) {
+				current, found = v, true
+
+				break
+			}
+		}
+
+		if !found {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
+
+// assignConfigValue sets v from a decoded file value.
+func assignConfigValue(v reflect.Value, raw any) error {
+	if items, ok := raw.([]any); ok {
+		if v.Kind() != reflect.Slice {
+			return fmt.Errorf("cannot assign a list to %v", v.Type())
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(items), len(items))
+		for i, item := range items {
+			if err := assignConfigValue(slice.Index(i), item); err != nil {
+				return err
+			}
+		}
+
+		v.Set(slice)
+
+		return nil
+	}
+
+	switch raw := raw.(type) {
+	case string:
+		return setConfigValue(v, raw)
+	case float64:
+		return setConfigValue(v, strconv.FormatFloat(raw, 'f', -1, 64))
+	default:
+		return setConfigValue(v, fmt.Sprint(raw))
+	}
+}
+
+// setConfigValue parses s into v. Slices are comma separated.
+func setConfigValue(v reflect.Value, s string) error {
+	if v.Type() == reflect.TypeFor[time.Duration]() {
+		d, err := time.ParseDuration(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(int64(d))
+
+		return nil
+	}
+
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(s)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(s)
+		if err != nil {
+			return err
+		}
+
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		n, err := strconv.ParseInt(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetInt(n)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		n, err := strconv.ParseUint(s, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetUint(n)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(s, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+
+		v.SetFloat(f)
+	case reflect.Slice:
+		var parts []string
+		if s != "" {
+			parts = strings.Split(s, ",")
+		}
+
+		slice := reflect.MakeSlice(v.Type(), len(parts), len(parts))
+		for i, part := range parts {
+			if err := setConfigValue(slice.Index(i), strings.TrimSpace(part)); err != nil {
+				return err
+			}
+		}
+
+		v.Set(slice)
+	default:
+		return fmt.Errorf("unsupported config field type %v", v.Type())
+	}
+
+	return nil
+}
@@ -0,0 +1,214 @@
+package di_test
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+type ServerConfig struct {
+	Host    string        `config:"host" env:"HOST" flag:"host" default:"localhost"`
+	Port    int           `config:"port" env:"PORT" flag:"port" default:"8080"`
+	Timeout time.Duration `config:"timeout" default:"5s"`
+	Tags    []string      `config:"tags" env:"TAGS"`
+	DB      struct {
+		DSN      string `config:"dsn,required" env:"DB_DSN"`
+		MaxConns int    `config:"max_conns"`
+	} `config:"db"`
+}
+
+func (c *ServerConfig) Validate() error {
+	if c.Port <= 0 {
+		return errors.New("port must be positive")
+	}
+
+	return nil
+}
+
+func writeConfigFile(t *testing.T, name, content string) string {
+	t.Helper()
+
+	path := filepath.Join(t.TempDir(), name)
+	require.NoError(t, os.WriteFile(path, []byte(content), 0o600))
+
+	return path
+}
+
+func TestLoadConfig(t *testing.T) {
+	t.Run("defaults and yaml", func(t *testing.T) {
+		path := writeConfigFile(t, "app.yaml", "port: 9090\ntags: [a, b]\ndb:\n  dsn: postgres://db\n  max_conns: 10\n")
+
+		cfg, err := di.LoadConfig[ServerConfig](di.ConfigFile(path))
+		require.NoError(t, err)
+		require.Equal(t, "localhost", cfg.Host)
+		require.Equal(t, 9090, cfg.Port)
+		require.Equal(t, 5*time.Second, cfg.Timeout)
+		require.Equal(t, []string{"a", "b"}, cfg.Tags)
+		require.Equal(t, "postgres://db", cfg.DB.DSN)
+		require.Equal(t, 10, cfg.DB.MaxConns)
+	})
+
+	t.Run("json, env and flags by precedence", func(t *testing.T) {
+		path := writeConfigFile(t, "app.json", `{"host": "file", "port": 1000, "db": {"dsn": "file-dsn"}}`)
+		t.Setenv("APP_HOST", "env")
+		t.Setenv("APP_PORT", "2000")
+		t.Setenv("APP_TAGS", "x, y")
+
+		cfg, err := di.LoadConfig[ServerConfig](
+			di.ConfigFile(path),
+			di.ConfigEnvPrefix("APP_"),
+			di.ConfigFlags([]string{"-port", "3000"}),
+		)
+		require.NoError(t, err)
+		require.Equal(t, "env", cfg.Host)
+		require.Equal(t, 3000, cfg.Port)
+		require.Equal(t, []string{"x", "y"}, cfg.Tags)
+		require.Equal(t, "file-dsn", cfg.DB.DSN)
+	})
+
+	t.Run("toml", func(t *testing.T) {
+		path := writeConfigFile(t, "app.toml", "port = 9090\ntags = [\"a\", \"b\"]\n\n[db]\ndsn = \"postgres://db\"\nmax_conns = 10\n")
+
+		cfg, err := di.LoadConfig[ServerConfig](di.ConfigFile(path))
+		require.NoError(t, err)
+		require.Equal(t, 9090, cfg.Port)
+		require.Equal(t, []string{"a", "b"}, cfg.Tags)
+		require.Equal(t, "postgres://db", cfg.DB.DSN)
+		require.Equal(t, 10, cfg.DB.MaxConns)
+	})
+
+	t.Run("custom decoder", func(t *testing.T) {
+		path := writeConfigFile(t, "app.properties", "port=7070\ndb.dsn=props\n")
+
+		_, err := di.LoadConfig[ServerConfig](di.ConfigFile(path))
+		require.ErrorContains(t, err, `no config decoder for ".properties" files`)
+
+		cfg, err := di.LoadConfig[ServerConfig](di.ConfigFile(path), di.ConfigDecoder(".properties", decodeProperties))
+		require.NoError(t, err)
+		require.Equal(t, 7070, cfg.Port)
+		require.Equal(t, "props", cfg.DB.DSN)
+	})
+
+	t.Run("optional file", func(t *testing.T) {
+		t.Setenv("DB_DSN", "env-dsn")
+
+		cfg, err := di.LoadConfig[ServerConfig](di.ConfigOptionalFile(filepath.Join(t.TempDir(), "missing.yaml")))
+		require.NoError(t, err)
+		require.Equal(t, "env-dsn", cfg.DB.DSN)
+
+		_, err = di.LoadConfig[ServerConfig](di.ConfigFile(filepath.Join(t.TempDir(), "missing.yaml")))
+		require.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("required and invalid values", func(t *testing.T) {
+		t.Setenv("PORT", "abc")
+
+		_, err := di.LoadConfig[ServerConfig]()
+		require.ErrorContains(t, err, "Port from env PORT")
+		require.ErrorContains(t, err, "required config field DB.DSN is not set")
+	})
+
+	t.Run("default does not satisfy required", func(t *testing.T) {
+		type Config struct {
+			DSN string `config:",required" default:"dsn"`
+		}
+
+		_, err := di.LoadConfig[Config]()
+		require.ErrorContains(t, err, "required config field DSN is not set")
+	})
+
+	t.Run("duplicate flag", func(t *testing.T) {
+		type Config struct {
+			Host string `flag:"addr"`
+			Bind string `flag:"addr"`
+		}
+
+		_, err := di.LoadConfig[Config](di.ConfigFlags(nil))
+		require.ErrorContains(t, err, "flag -addr is used by both Host and Bind")
+	})
+
+	t.Run("bool flags", func(t *testing.T) {
+		type Config struct {
+			Debug   bool   `flag:"debug"`
+			Verbose bool   `flag:"v"`
+			Host    string `flag:"host"`
+		}
+
+		cfg, err := di.LoadConfig[Config](di.ConfigFlags([]string{"-debug", "-host", "example.com", "-v=false"}))
+		require.NoError(t, err)
+		require.True(t, cfg.Debug)
+		require.False(t, cfg.Verbose)
+		require.Equal(t, "example.com", cfg.Host)
+
+		_, err = di.LoadConfig[Config](di.ConfigFlags([]string{"-unknown"}))
+		require.ErrorContains(t, err, "flag provided but not defined: -unknown")
+	})
+
+	t.Run("validate", func(t *testing.T) {
+		t.Setenv("PORT", "-1")
+		t.Setenv("DB_DSN", "dsn")
+
+		_, err := di.LoadConfig[ServerConfig]()
+		require.ErrorContains(t, err, "port must be positive")
+	})
+}
+
+// decodeProperties decodes "a.b=value" lines into nested maps.
+func decodeProperties(data []byte, v any) error {
+	values := *v.(*map[string]any)
+	for _, line := range strings.Split(string(data), "\n") {
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			continue
+		}
+
+		keys := strings.Split(key, ".")
+		m := values
+		for _, k := range keys[:len(keys)-1] {
+			if _, ok := m[k].(map[string]any); !ok {
+				m[k] = make(map[string]any)
+			}
+			m = m[k].(map[string]any)
+		}
+		m[keys[len(keys)-1]] = value
+	}
+
+	return nil
+}
+
+type EnvDBConfig struct {
+	DSN string `config:",required" env:"DSN"`
+}
+
+func NewDBClientFromEnv(cfg *EnvDBConfig) *DBClientImpl {
+	return NewDBClient(cfg.DSN)
+}
+
+func TestProvideConfig(t *testing.T) {
+	t.Setenv("DB_DSN", "from-env")
+
+	c := di.New()
+	di.ProvideConfig[EnvDBConfig](c, di.ConfigEnvPrefix("DB_"))
+	c.Provide(NewDBClientFromEnv)
+
+	db, err := di.Resolve[*DBClientImpl](c)
+	require.NoError(t, err)
+	require.Equal(t, "from-env", db.data)
+
+	c = di.New()
+	di.ProvideConfig[EnvDBConfig](c)
+	c.Provide(NewDBClientFromEnv)
+
+	_, err = di.Resolve[*DBClientImpl](c)
+	var ctorErr *di.ConstructorError
+	require.ErrorAs(t, err, &ctorErr)
+	require.ErrorContains(t, err, "ProvideConfig(di_test.EnvDBConfig)")
+	require.ErrorContains(t, err, "required config field DSN is not set")
+}
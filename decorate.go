@@ -0,0 +1,76 @@
+package di
+
+import (
+	"reflect"
+)
+
+// Decorate registers a decorator that replaces instances of type T, where T is both the
+// type of the first parameter and of the first result of decorator, e.g.
+// func(r Repo, m *Metrics) Repo. The other parameters are injected like constructor
+// parameters. Decorators of the same type are applied in registration order before the
+// instance is cached. Decorators of an interface type also apply to implementations
+// resolved as that interface.
+func (c *Container) Decorate(decorator any) {
+	if c.parent != nil {
+		panic("decorators must be registered in the root container")
+	}
+
+	prvdr := newProvider(decorator)
+	if len(prvdr.paramTypes) == 0 || prvdr.paramTypes[0] != prvdr.returnType {
+		panic("decorator must take the decorated value as its first parameter and return the same type")
+	}
+
+	prvdr.decorator = true
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.decorators = append(c.decorators, prvdr)
+}
+
+func (c *Container) getDecorators() []*Provider {
+	if c.parent == nil {
+		return c.decorators
+	}
+
+	root := c.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	return root.decorators
+}
+
+func (c *Container) decorates(t reflect.Type) bool {
+	for _, d := range c.getDecorators() {
+		if d.returnType == t {
+			return true
+		}
+	}
+
+	return false
+}
+
+// decorate applies the decorators registered for type t to inst.
+func (c *Container) decorate(inst reflect.Value, t reflect.Type) (reflect.Value, error) {
+	for _, d := range c.getDecorators() {
+		if d.returnType != t {
+			continue
+		}
+
+		args := make([]reflect.Value, len(d.paramTypes))
+		args[0] = inst
+
+		c.trackDependency(d)
+		c.resolving = append(c.resolving, d)
+		decorated, err := c.call(d, args)
+		c.resolving = c.resolving[:len(c.resolving)-1]
+
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		inst = decorated
+	}
+
+	return inst, nil
+}
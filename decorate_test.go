@@ -0,0 +1,75 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+type cachedRepo struct {
+	Repo
+	prefix string
+}
+
+func (r *cachedRepo) Find() (string, error) {
+	data, err := r.Repo.Find()
+
+	return r.prefix + data, err
+}
+
+type loggedDBClient struct {
+	*DBClientImpl
+}
+
+func (c *loggedDBClient) Exec() (string, error) {
+	data, err := c.DBClientImpl.Exec()
+
+	return "logged " + data, err
+}
+
+func TestContainer_DecorateInterface(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(NewRepo)
+	c.Provide(func() string { return "cached " })
+	c.Decorate(func(r Repo, prefix string) Repo { return &cachedRepo{Repo: r, prefix: prefix} })
+	c.Decorate(func(r Repo) Repo { return &cachedRepo{Repo: r, prefix: "outer "} })
+
+	repo := di.MustResolve[Repo](c)
+	data, err := repo.Find()
+	require.NoError(t, err)
+	require.Equal(t, "outer cached data", data)
+	require.Same(t, repo, di.MustResolve[Repo](c))
+
+	impl := di.MustResolve[*RepoImpl](c)
+	require.Same(t, impl, repo.(*cachedRepo).Repo.(*cachedRepo).Repo)
+}
+
+func TestContainer_DecorateConcrete(t *testing.T) {
+	c := di.New()
+	c.Provide(func() *DBClientImpl { return NewDBClient("data") })
+	c.Provide(func(db *DBClientImpl) DBClient { return &loggedDBClient{DBClientImpl: db} })
+	c.Decorate(func(db *DBClientImpl) *DBClientImpl { return NewDBClient("decorated " + db.data) })
+
+	db := di.MustResolve[DBClient](c)
+	data, err := db.Exec()
+	require.NoError(t, err)
+	require.Equal(t, "logged decorated data", data)
+	require.Same(t, db.(*loggedDBClient).DBClientImpl, di.MustResolve[*DBClientImpl](c))
+}
+
+func TestContainer_DecorateError(t *testing.T) {
+	errDecorate := errors.New("decorate error")
+
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Decorate(func(db *DBClientImpl) (*DBClientImpl, error) { return nil, errDecorate })
+
+	_, err := di.Resolve[*DBClientImpl](c)
+	require.ErrorIs(t, err, errDecorate)
+
+	require.Panics(t, func() { c.Decorate(func(db *DBClientImpl) Repo { return nil }) })
+}
@@ -1,75 +1,112 @@
 package di
 
 import (
+	"errors"
 	"fmt"
 	"reflect"
+	"slices"
+	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
 )
 
 type Container struct {
 	mu        sync.Mutex
+	parent    *Container
 	providers []*Provider
-	instances map[reflect.Type]reflect.Value
+	modules   map[string]*Module
+	instances map[instanceKey]reflect.Value
+
+	decorators  []*Provider
+	observers   []Observer
+	observersMu sync.Mutex
+
+	instancesList []instanceEntry
+	dependencies  map[*Provider][]*Provider
+	resolving     []*Provider
+	deferredFor   *Provider
+	pending       []*atomic.Bool
+}
 
-	instancesList []any
-	resolvedMap   map[reflect.Type]struct{}
+// instanceEntry is a cached instance of its provider's own type, in construction order.
+type instanceEntry struct {
+	provider *Provider
+	value    any
 }
 
 func New() *Container {
 	return &Container{
-		instances:   make(map[reflect.Type]reflect.Value),
-		resolvedMap: make(map[reflect.Type]struct{}),
+		instances: make(map[instanceKey]reflect.Value),
 	}
 }
 
-func (c *Container) Provide(constructor any) *Provider {
-	c.mu.Lock()
-	defer c.mu.Unlock()
-
-	prvdr := newProvider(constructor)
-	for _, pr := range c.providers {
-		if pr.returnType == prvdr.returnType {
-			panic(fmt.Errorf("duplicate provider %v", pr.returnType))
-		}
+// NewScope creates a child container with its own cache for scoped instances.
+// Singletons are still resolved from and cached in the root container.
+func (c *Container) NewScope() *Container {
+	return &Container{
+		parent:    c,
+		instances: make(map[instanceKey]reflect.Value),
 	}
+}
 
-	c.providers = append(c.providers, prvdr)
+func (c *Container) Provide(constructor any) *Provider {
+	prvdr := newProvider(constructor)
+	c.register(prvdr)
 
 	return prvdr
 }
 
 func (c *Container) Resolve(target any) error {
+	return c.ResolveNamed(target, "")
+}
+
+// ResolveNamed resolves the provider registered with Provider.Named(name) into target.
+func (c *Container) ResolveNamed(target any, name string) error {
 	c.mu.Lock()
-	defer c.mu.Unlock()
+	defer c.unlock()
 
 	ptrVal := reflect.ValueOf(target)
 	if ptrVal.Kind() != reflect.Ptr {
 		return fmt.Errorf("expected a pointer")
 	}
 
-	elemType := ptrVal.Elem().Type()
+	inst, err := c.getInstanceByType(ptrVal.Elem().Type(), name)
+	if err != nil {
+		return err
+	}
 
-	if ptrVal.Elem().Kind() == reflect.Interface {
-		impl, err := c.getInstanceByInterface(elemType)
-		if err != nil {
-			return fmt.Errorf("%w [target=%s]", err, getFuncName(ptrVal))
-		}
+	ptrVal.Elem().Set(inst)
+
+	return nil
+}
 
-		ptrVal.Elem().Set(impl)
+// ResolveGroup resolves every provider of the group into target, which must be a pointer
+// to a slice. Instances are appended in registration order.
+func (c *Container) ResolveGroup(target any, group string) error {
+	c.mu.Lock()
+	defer c.unlock()
 
-		return nil
+	ptrVal := reflect.ValueOf(target)
+	if ptrVal.Kind() != reflect.Ptr || ptrVal.Elem().Kind() != reflect.Slice {
+		return fmt.Errorf("expected a pointer to a slice")
 	}
 
-	inst, err := c.getInstanceByType(elemType)
+	inst, err := c.getGroup(ptrVal.Elem().Type(), group)
 	if err != nil {
 		return err
 	}
 
-	ptrVal.Elem().Set(reflect.ValueOf(inst))
+	ptrVal.Elem().Set(inst)
 
 	return nil
 }
 
+// ResolveToStruct injects the exported fields of the struct target points to. Fields are
+// configured with the di tag: `di:"-"` skips a field, `di:"name=replica"` requests a named
+// provider, `di:"optional"` leaves the zero value when no provider exists and
+// `di:"group=routes"` collects a value group into a slice field. Untagged embedded structs
+// are injected recursively. Errors of all fields are returned together.
 func (c *Container) ResolveToStruct(target any) error {
 	ptrVal := reflect.ValueOf(target)
 
@@ -83,65 +120,345 @@ func (c *Container) ResolveToStruct(target any) error {
 		return fmt.Errorf("target must point to a struct")
 	}
 
-	elemType := elemVal.Type()
-	for i := 0; i < elemType.NumField(); i++ {
-		field := elemType.Field(i)
-		fieldValue := elemVal.Field(i)
+	c.mu.Lock()
+	defer c.unlock()
+
+	return c.resolveStruct(elemVal, "")
+}
+
+// resolveStruct injects the settable fields of v, descending into embedded structs.
+// Errors of all fields are joined.
+func (c *Container) resolveStruct(v reflect.Value, path string) error {
+	var errs []error
+
+	typ := v.Type()
+	for i := 0; i < typ.NumField(); i++ {
+		field := typ.Field(i)
+		fieldValue := v.Field(i)
 
 		if !fieldValue.CanSet() {
 			continue
 		}
 
-		fieldValuePtr := fieldValue.Addr().Interface()
+		rawTag := field.Tag.Get(tagName)
+		tag, err := parseTag(rawTag)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("field %q: %w", path+field.Name, err))
 
-		if err := c.Resolve(fieldValuePtr); err != nil {
-			return fmt.Errorf("failed to resolve field %q: %w", field.Name, err)
+			continue
 		}
+
+		if tag.skip {
+			continue
+		}
+
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && rawTag == "" {
+			if err := c.resolveStruct(fieldValue, path+field.Name+"."); err != nil {
+				errs = append(errs, err)
+			}
+
+			continue
+		}
+
+		inst, err := c.resolveField(field.Type, tag)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("failed to resolve field %q: %w", path+field.Name, err))
+
+			continue
+		}
+
+		fieldValue.Set(inst)
 	}
 
-	return nil
+	return errors.Join(errs...)
 }
 
-func (c *Container) getInstanceByInterface(ifaceType reflect.Type) (reflect.Value, error) {
-	if val, ok := c.instances[ifaceType]; ok {
-		return val, nil
+// register adds the provider and its Out field providers and panics on a duplicate.
+// Providers registered directly on the container may still be named by a chained Named,
+// so an unnamed one is checked once Named is called; until then Resolve and Validate
+// report it. Module providers are complete when their module is installed.
+func (c *Container) register(prvdr *Provider) {
+	if c.parent != nil {
+		panic("providers must be registered in the root container")
 	}
 
-	for _, prov := range c.providers {
-		if prov.returnType.Implements(ifaceType) {
-			inst, err := c.buildInstance(prov)
-			if err != nil {
-				return reflect.Value{}, err
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.providers = append(c.providers, prvdr)
+	c.providers = append(c.providers, prvdr.outs...)
+
+	prvdr.container = c
+	c.checkDuplicate(prvdr, prvdr.module == nil)
+	for _, out := range prvdr.outs {
+		out.container = c
+		c.checkDuplicate(out, false)
+	}
+}
+
+// checkDuplicate panics if another provider shares the type, name and private module of
+// prvdr. A tentative unnamed provider is not checked yet. c.mu must be held.
+func (c *Container) checkDuplicate(prvdr *Provider, tentative bool) {
+	if tentative && prvdr.named == "" {
+		return
+	}
+
+	key := registrationKey(prvdr)
+	for _, other := range c.providers {
+		if other != prvdr && registrationKey(other) == key {
+			panic(fmt.Errorf("duplicate provider %s: %s", key, providerNames([]*Provider{other, prvdr})))
+		}
+	}
+}
+
+// recheck checks prvdr for duplicates again after its key has changed.
+func (c *Container) recheck(prvdr *Provider) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.checkDuplicate(prvdr, false)
+}
+
+func (c *Container) root() *Container {
+	root := c
+	for root.parent != nil {
+		root = root.parent
+	}
+
+	return root
+}
+
+func (c *Container) getProviders() []*Provider {
+	if c.parent == nil {
+		return c.providers
+	}
+
+	root := c.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	return root.providers
+}
+
+// requester returns the constructor currently being built, or nil outside of a resolution.
+func (c *Container) requester() *Provider {
+	if len(c.resolving) > 0 {
+		return c.resolving[len(c.resolving)-1]
+	}
+
+	return c.deferredFor
+}
+
+// visibleProviders returns the providers visible to the constructor currently being built.
+func (c *Container) visibleProviders() []*Provider {
+	return visibleTo(c.getProviders(), c.requester())
+}
+
+func (c *Container) getInstanceByType(t reflect.Type, name string) (reflect.Value, error) {
+	found, err := findProvider(c.visibleProviders(), t, name)
+	if err != nil {
+		return reflect.Value{}, wrapChain(c.resolving, err)
+	}
+
+	if found != nil {
+		return c.getInstance(found, t)
+	}
+
+	if name == "" && t.Kind() == reflect.Slice && t.Elem().Kind() == reflect.Interface {
+		return c.getGroup(t, "")
+	}
+
+	return reflect.Value{}, &MissingDependencyError{Type: t, Name: name, Chain: newChain(c.resolving)}
+}
+
+// getGroup collects, in registration order, the instances of every provider in the group
+// whose type matches the element type of sliceType. An empty group matches all providers
+// except the requesting one, so a composite may collect the implementations it wraps.
+func (c *Container) getGroup(sliceType reflect.Type, group string) (reflect.Value, error) {
+	elemType := sliceType.Elem()
+	result := reflect.MakeSlice(sliceType, 0, 0)
+	requester := c.requester()
+	for _, prov := range c.visibleProviders() {
+		if group != "" && prov.group != group {
+			continue
+		}
+
+		if group == "" && prov == requester {
+			continue
+		}
+
+		if !providesType(prov, elemType) {
+			continue
+		}
+
+		inst, err := c.getInstance(prov, elemType)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		result = reflect.Append(result, inst)
+	}
+
+	return result, nil
+}
+
+// instanceKey identifies a cached instance. typ is the provider's own type, or the
+// interface it was resolved as when decorators are registered for that interface.
+type instanceKey struct {
+	provider *Provider
+	typ      reflect.Type
+}
+
+// getInstance returns the instance of p resolved as type as, honouring the provider lifetime.
+func (c *Container) getInstance(p *Provider, as reflect.Type) (reflect.Value, error) {
+	c.trackDependency(p)
+
+	if as != p.returnType && !c.decorates(as) {
+		as = p.returnType
+	}
+
+	switch p.lifetime {
+	case Singleton:
+		if c.parent != nil {
+			root := c.root()
+			root.mu.Lock()
+			defer root.unlock()
+
+			// Continue the chain of the scope so errors show the full path.
+			root.resolving = slices.Clone(c.resolving)
+			defer func() { root.resolving = nil }()
+
+			return root.getInstance(p, as)
+		}
+	case Scoped:
+		if c.parent == nil {
+			if c.resolvingSingleton() {
+				return reflect.Value{}, wrapChain(c.resolving,
+					fmt.Errorf("singleton cannot depend on scoped provider %s", p.name))
 			}
 
-			c.instances[ifaceType] = inst
+			return reflect.Value{}, wrapChain(c.resolving,
+				fmt.Errorf("scoped provider %s must be resolved from a scope", p.name))
+		}
+	case Transient:
+		return c.newInstance(p, as)
+	}
+
+	key := instanceKey{provider: p, typ: as}
+	if val, ok := c.instances[key]; ok {
+		return val, nil
+	}
+
+	inst, err := c.newInstance(p, as)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	c.instances[key] = inst
+	if as == p.returnType {
+		c.instancesList = append(c.instancesList, instanceEntry{provider: p, value: inst.Interface()})
+	}
+
+	return inst, nil
+}
+
+// newInstance builds an instance of p, decorated as type as on top of the instance of the provider's own type.
+func (c *Container) newInstance(p *Provider, as reflect.Type) (reflect.Value, error) {
+	if as == p.returnType {
+		return c.buildInstance(p)
+	}
+
+	base, err := c.getInstance(p, p.returnType)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return c.decorate(base, as)
+}
 
-			return inst, nil
+// trackDependency records that the constructor being built depends on p.
+// Deferred resolutions are not recorded.
+func (c *Container) trackDependency(p *Provider) {
+	if len(c.resolving) == 0 {
+		return
+	}
+
+	requester := c.resolving[len(c.resolving)-1]
+	if slices.Contains(c.dependencies[requester], p) {
+		return
+	}
+
+	if c.dependencies == nil {
+		c.dependencies = make(map[*Provider][]*Provider)
+	}
+
+	c.dependencies[requester] = append(c.dependencies[requester], p)
+}
+
+func (c *Container) resolvingSingleton() bool {
+	for _, prov := range c.resolving {
+		if prov.lifetime == Singleton {
+			return true
 		}
 	}
 
-	return reflect.Value{}, fmt.Errorf("no provider found for interface %v", ifaceType)
+	return false
 }
 
 func (c *Container) buildInstance(p *Provider) (reflect.Value, error) {
-	if _, ok := c.resolvedMap[p.returnType]; ok {
-		return reflect.Value{}, fmt.Errorf("circular dependency detected: %v", p.name)
+	if idx := slices.Index(c.resolving, p); idx >= 0 {
+		return reflect.Value{}, &CycleError{Chain: newChain(append(slices.Clone(c.resolving[idx:]), p))}
+	}
+
+	c.resolving = append(c.resolving, p)
+	defer func() { c.resolving = c.resolving[:len(c.resolving)-1] }()
+
+	frame := p.frame()
+	c.emit(ProviderBuildStarted{Provider: frame})
+	started := time.Now()
+
+	inst, err := c.call(p, make([]reflect.Value, len(p.paramTypes)))
+	if err == nil {
+		inst, err = c.decorate(inst, p.returnType)
 	}
 
-	c.resolvedMap[p.returnType] = struct{}{}
-	defer delete(c.resolvedMap, p.returnType)
+	c.emit(ProviderBuildFinished{Provider: frame, Duration: time.Since(started), Err: err})
 
-	args := make([]any, len(p.paramTypes))
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	return inst, nil
+}
+
+// call resolves the arguments of p that are not set yet and invokes its constructor.
+// p must be on top of the resolving stack.
+func (c *Container) call(p *Provider, args []reflect.Value) (reflect.Value, error) {
 	for i, pt := range p.paramTypes {
+		if args[i].IsValid() {
+			continue
+		}
+
+		if b, ok := p.bindings[i]; ok {
+			arg, err := c.resolveBinding(pt, b)
+			if err != nil {
+				return reflect.Value{}, err
+			}
+
+			args[i] = arg
+
+			continue
+		}
+
 		if arg, ok := p.args[pt]; ok {
-			args[i] = arg.Interface()
+			args[i] = arg
 
 			continue
 		}
 
-		arg, err := c.getInstanceByType(pt)
+		arg, err := c.resolveParam(pt, "", false)
 		if err != nil {
-			return reflect.Value{}, fmt.Errorf("%w [constructor: %s]", err, p.name)
+			return reflect.Value{}, err
 		}
 
 		args[i] = arg
@@ -149,32 +466,112 @@ func (c *Container) buildInstance(p *Provider) (reflect.Value, error) {
 
 	result, err := p.initFunc(args)
 	if err != nil {
-		return reflect.Value{}, err
+		return reflect.Value{}, &ConstructorError{Chain: newChain(c.resolving), Err: err}
 	}
 
-	c.instancesList = append(c.instancesList, result)
+	if result == nil {
+		return reflect.Zero(p.returnType), nil
+	}
 
 	return reflect.ValueOf(result), nil
 }
 
-func (c *Container) getInstanceByType(t reflect.Type) (any, error) {
-	if val, ok := c.instances[t]; ok {
-		return val.Interface(), nil
+func (c *Container) resolveBinding(pt reflect.Type, b binding) (reflect.Value, error) {
+	switch {
+	case b.value.IsValid():
+		return b.value, nil
+	case b.fn != nil:
+		c.trackDependency(b.fn)
+		c.resolving = append(c.resolving, b.fn)
+		defer func() { c.resolving = c.resolving[:len(c.resolving)-1] }()
+
+		return c.call(b.fn, make([]reflect.Value, len(b.fn.paramTypes)))
+	case b.provider != nil:
+		return c.getInstance(b.provider, b.provider.returnType)
+	default:
+		return c.resolveParam(pt, b.name, false)
 	}
+}
 
-	for _, prov := range c.providers {
-		if prov.returnType.AssignableTo(t) ||
-			(prov.returnType.Kind() == reflect.Interface && prov.returnType.Implements(t)) {
-			inst, err := c.buildInstance(prov)
-			if err != nil {
-				return reflect.Value{}, err
-			}
+func providerKey(t reflect.Type, name string) string {
+	if name == "" {
+		return t.String()
+	}
+
+	return fmt.Sprintf("%v named %q", t, name)
+}
+
+// registrationKey identifies a provider among the registered ones. Private providers of
+// different modules do not clash.
+func registrationKey(p *Provider) string {
+	key := providerKey(p.returnType, p.named)
+	if p.private {
+		key += fmt.Sprintf(" in module %q", p.moduleName())
+	}
 
-			c.instances[t] = inst
+	return key
+}
+
+// findProvider selects the provider for the requested type and name. A provider registered
+// for exactly that type wins; otherwise providers declared with As, then Primary ones narrow
+// the candidates down. Several remaining candidates are reported as ambiguous.
+// It returns nil without an error when nothing matches.
+func findProvider(providers []*Provider, t reflect.Type, name string) (*Provider, error) {
+	var candidates, exact, declared []*Provider
+	for _, prov := range providers {
+		if prov.named != name || !providesType(prov, t) {
+			continue
+		}
 
-			return inst.Interface(), nil
+		candidates = append(candidates, prov)
+		if prov.returnType == t {
+			exact = append(exact, prov)
 		}
+		if prov.declares(t) {
+			declared = append(declared, prov)
+		}
+	}
+
+	switch {
+	case len(exact) > 1:
+		return nil, fmt.Errorf("duplicate provider %v: %s", providerKey(t, name), providerNames(exact))
+	case len(exact) == 1:
+		return exact[0], nil
+	case len(declared) > 0:
+		candidates = declared
 	}
 
-	return reflect.Value{}, fmt.Errorf("no provider\\arg found for type %v", t)
+	if len(candidates) > 1 {
+		var primary []*Provider
+		for _, prov := range candidates {
+			if prov.primary {
+				primary = append(primary, prov)
+			}
+		}
+
+		if len(primary) == 1 {
+			return primary[0], nil
+		}
+
+		return nil, fmt.Errorf("ambiguous providers for %v: %s", providerKey(t, name), providerNames(candidates))
+	}
+
+	if len(candidates) == 1 {
+		return candidates[0], nil
+	}
+
+	return nil, nil
+}
+
+func providerNames(providers []*Provider) string {
+	names := make([]string, 0, len(providers))
+	for _, prov := range providers {
+		names = append(names, prov.name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+func providesType(p *Provider, t reflect.Type) bool {
+	return p.returnType.AssignableTo(t)
 }
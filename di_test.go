@@ -1,6 +1,7 @@
 package di_test
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 
@@ -220,3 +221,333 @@ func TestContainer_ResolveToStruct(t *testing.T) {
 	require.NotNil(t, holder.Service2)
 	require.NotNil(t, holder.Root)
 }
+
+type RequestCtx struct {
+	id int
+}
+
+type Handler struct {
+	req  *RequestCtx
+	repo Repo
+}
+
+func NewHandler(req *RequestCtx, repo Repo) *Handler {
+	return &Handler{req: req, repo: repo}
+}
+
+func TestContainer_TransientLifetime(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(NewRepo).Transient()
+
+	repo1, err := di.Resolve[*RepoImpl](c)
+	require.NoError(t, err)
+	repo2, err := di.Resolve[*RepoImpl](c)
+	require.NoError(t, err)
+
+	require.NotSame(t, repo1, repo2)
+	require.Same(t, repo1.db, repo2.db)
+}
+
+func TestContainer_ScopedLifetime(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(NewRepo)
+	counter := 0
+	c.Provide(func() *RequestCtx {
+		counter++
+
+		return &RequestCtx{id: counter}
+	}).Scoped()
+	c.Provide(NewHandler).Scoped()
+
+	_, err := di.Resolve[*Handler](c)
+	require.Error(t, err)
+
+	scope1 := c.NewScope()
+	h1 := di.MustResolve[*Handler](scope1)
+	require.Same(t, h1, di.MustResolve[*Handler](scope1))
+	require.Same(t, h1.req, di.MustResolve[*RequestCtx](scope1))
+
+	scope2 := c.NewScope()
+	h2 := di.MustResolve[*Handler](scope2)
+	require.NotSame(t, h1, h2)
+	require.NotEqual(t, h1.req.id, h2.req.id)
+
+	require.Same(t, h1.repo, h2.repo)
+	require.Same(t, h1.repo, di.MustResolve[Repo](c))
+}
+
+func TestContainer_SingletonCapturesScoped(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(NewRepo)
+	c.Provide(func() *RequestCtx { return &RequestCtx{} }).Scoped()
+	c.Provide(NewHandler)
+
+	_, err := di.Resolve[*Handler](c.NewScope())
+	require.ErrorContains(t, err, "singleton cannot depend on scoped")
+}
+
+func TestContainer_NamedProviders(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("primary")
+	c.Provide(func() *DBClientImpl { return NewDBClient("replica") }).Named("replica")
+
+	primary, err := di.Resolve[*DBClientImpl](c)
+	require.NoError(t, err)
+	require.Equal(t, "primary", primary.data)
+
+	replica, err := di.Named[DBClient](c, "replica")
+	require.NoError(t, err)
+	data, _ := replica.Exec()
+	require.Equal(t, "replica", data)
+
+	_, err = di.Named[DBClient](c, "missing")
+	require.ErrorContains(t, err, `named "missing"`)
+
+	type Holder struct {
+		Primary *DBClientImpl
+		Replica DBClient `di:"name=replica"`
+	}
+
+	var holder Holder
+	require.NoError(t, c.ResolveToStruct(&holder))
+	require.Same(t, primary, holder.Primary)
+	require.Same(t, replica, holder.Replica)
+}
+
+func TestContainer_DuplicateProvider(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("a")
+	c.Provide(NewDBClient).Arg("b")
+
+	_, err := di.Resolve[*DBClientImpl](c)
+	require.ErrorContains(t, err, "duplicate provider")
+
+	c = di.New()
+	c.Provide(NewDBClient).Arg("a").Named("x")
+	require.PanicsWithError(t,
+		`duplicate provider *di_test.DBClientImpl named "x": `+
+			"github.com/rom8726/di_test.NewDBClient, github.com/rom8726/di_test.NewDBClient",
+		func() { c.Provide(NewDBClient).Arg("b").Named("x") })
+
+	c = di.New()
+	c.Provide(NewDBClient).Arg("a")
+	require.NotPanics(t, func() { c.Provide(NewDBClient).Arg("b").Named("x") })
+}
+
+type Migration interface {
+	Version() int
+}
+
+type migration int
+
+func (m migration) Version() int { return int(m) }
+
+type Migrator struct {
+	migrations []Migration
+}
+
+func NewMigrator(migrations []Migration) *Migrator {
+	return &Migrator{migrations: migrations}
+}
+
+func TestContainer_ValueGroups(t *testing.T) {
+	c := di.New()
+	c.Provide(func() migration { return 1 }).Group("migrations")
+	c.Provide(func() *DBClientImpl { return NewDBClient("data") })
+	c.Provide(func() Migration { return migration(2) }).Group("migrations")
+	c.Provide(func() int { return 3 })
+	c.Provide(NewMigrator)
+
+	migrator, err := di.Resolve[*Migrator](c)
+	require.NoError(t, err)
+	require.Len(t, migrator.migrations, 2)
+	require.Equal(t, 1, migrator.migrations[0].Version())
+	require.Equal(t, 2, migrator.migrations[1].Version())
+
+	group, err := di.ResolveGroup[Migration](c, "migrations")
+	require.NoError(t, err)
+	require.Equal(t, migrator.migrations, group)
+
+	empty, err := di.ResolveGroup[Migration](c, "unknown")
+	require.NoError(t, err)
+	require.Empty(t, empty)
+}
+
+type migrationSet struct {
+	migrations []Migration
+}
+
+func (s *migrationSet) Version() int {
+	return len(s.migrations)
+}
+
+func TestContainer_CompositeSliceInjection(t *testing.T) {
+	c := di.New()
+	c.Provide(func() migration { return 1 })
+	c.Provide(func(all []Migration) *migrationSet { return &migrationSet{migrations: all} })
+	c.Provide(func() Migration { return migration(2) })
+
+	require.NoError(t, c.Validate())
+
+	set, err := di.Resolve[*migrationSet](c)
+	require.NoError(t, err)
+	require.Equal(t, 2, set.Version())
+}
+
+type MockDBClient struct{}
+
+func (m *MockDBClient) Exec() (string, error) { return "mock", nil }
+
+func NewMockDBClient() *MockDBClient {
+	return &MockDBClient{}
+}
+
+func TestContainer_AmbiguousInterface(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(NewMockDBClient)
+	c.Provide(NewRepo)
+
+	_, err := di.Resolve[Repo](c)
+	require.ErrorContains(t, err, "ambiguous providers for di_test.DBClient")
+	require.ErrorContains(t, err, "NewDBClient")
+	require.ErrorContains(t, err, "NewMockDBClient")
+}
+
+func TestContainer_DisambiguateWithAs(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(NewMockDBClient).As(new(DBClient))
+	c.Provide(NewRepo)
+
+	repo := di.MustResolve[Repo](c)
+	data, err := repo.Find()
+	require.NoError(t, err)
+	require.Equal(t, "mock", data)
+
+	require.Panics(t, func() { c.Provide(NewRepo).As(new(DBClient)) })
+	require.Panics(t, func() { c.Provide(NewRepo).As(DBClient(nil)) })
+}
+
+func TestContainer_DisambiguateWithPrimary(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data").Primary()
+	c.Provide(NewMockDBClient)
+	c.Provide(NewRepo)
+
+	repo := di.MustResolve[Repo](c)
+	data, err := repo.Find()
+	require.NoError(t, err)
+	require.Equal(t, "data", data)
+}
+
+func TestContainer_ResolveToStructTags(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("primary")
+	c.Provide(func() *DBClientImpl { return NewDBClient("replica") }).Named("replica")
+	c.Provide(NewRepo)
+	c.Provide(func() migration { return 1 }).Group("migrations")
+
+	type Embedded struct {
+		Repo Repo
+	}
+
+	type Holder struct {
+		Embedded
+
+		Primary    *DBClientImpl
+		Replica    *DBClientImpl `di:"name=replica"`
+		Tracer     *Tracer       `di:"optional"`
+		Migrations []Migration   `di:"group=migrations"`
+		Plain      string        `di:"-"`
+	}
+
+	holder := Holder{Plain: "keep"}
+	require.NoError(t, c.ResolveToStruct(&holder))
+
+	require.NotNil(t, holder.Repo)
+	require.Equal(t, "primary", holder.Primary.data)
+	require.Equal(t, "replica", holder.Replica.data)
+	require.Nil(t, holder.Tracer)
+	require.Len(t, holder.Migrations, 1)
+	require.Equal(t, "keep", holder.Plain)
+}
+
+func TestContainer_ResolveToStructJoinsErrors(t *testing.T) {
+	c := di.New()
+
+	type Embedded struct {
+		Service Service
+	}
+
+	type Holder struct {
+		Embedded
+
+		Repo   Repo
+		Tracer *Tracer `di:"bad"`
+	}
+
+	var holder Holder
+	err := c.ResolveToStruct(&holder)
+	require.ErrorContains(t, err, `failed to resolve field "Embedded.Service"`)
+	require.ErrorContains(t, err, `failed to resolve field "Repo"`)
+	require.ErrorContains(t, err, `field "Tracer": unknown option "bad"`)
+}
+
+type Endpoint struct {
+	host string
+	port int
+	db   DBClient
+}
+
+func NewEndpoint(host string, path string, port int, db DBClient) *Endpoint {
+	return &Endpoint{host: host + path, port: port, db: db}
+}
+
+func TestProvider_ArgAt(t *testing.T) {
+	c := di.New()
+	c.Provide(func() *DBClientImpl { return NewDBClient("replica") }).Named("replica")
+	c.Provide(func() int { return 5432 })
+	c.Provide(NewEndpoint).
+		ArgAt(0, "localhost").
+		ArgAt(1, "/api").
+		ArgFunc(2, func(base int) int { return base + 1 }).
+		ArgNamed(3, "replica")
+
+	require.NoError(t, c.Validate())
+
+	ep, err := di.Resolve[*Endpoint](c)
+	require.NoError(t, err)
+	require.Equal(t, "localhost/api", ep.host)
+	require.Equal(t, 5433, ep.port)
+
+	data, _ := ep.db.Exec()
+	require.Equal(t, "replica", data)
+}
+
+func TestProvider_ArgAtValidation(t *testing.T) {
+	c := di.New()
+
+	require.Panics(t, func() { c.Provide(NewEndpoint).ArgAt(4, "x") })
+	require.Panics(t, func() { c.Provide(NewEndpoint).ArgAt(2, "x") })
+	require.Panics(t, func() { c.Provide(NewEndpoint).ArgAt(0, "a").ArgAt(0, "b") })
+	require.Panics(t, func() { c.Provide(NewEndpoint).ArgFunc(0, func() int { return 1 }) })
+	require.NotPanics(t, func() { c.Provide(NewEndpoint).ArgAt(3, nil) })
+}
+
+func TestProvider_ArgFuncError(t *testing.T) {
+	errPort := errors.New("port error")
+
+	c := di.New()
+	c.Provide(NewEndpoint).
+		ArgAt(0, "localhost").
+		ArgAt(1, "/").
+		ArgFunc(2, func() (int, error) { return 0, errPort }).
+		ArgAt(3, nil)
+
+	_, err := di.Resolve[*Endpoint](c)
+	require.ErrorIs(t, err, errPort)
+}
@@ -0,0 +1,147 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Frame is a single constructor in a resolution chain.
+type Frame struct {
+	Type        reflect.Type
+	Constructor string
+	Module      string
+	File        string
+	Line        int
+}
+
+func (f Frame) String() string {
+	if f.Module != "" {
+		return fmt.Sprintf("[%s] %s", f.Module, shortFuncName(f.Constructor))
+	}
+
+	return shortFuncName(f.Constructor)
+}
+
+// Chain is the path of constructors being built, outermost first.
+type Chain []Frame
+
+func (ch Chain) String() string {
+	parts := make([]string, 0, len(ch))
+	for _, frame := range ch {
+		parts = append(parts, frame.String())
+	}
+
+	return strings.Join(parts, " -> ")
+}
+
+// Trace renders the chain on several lines, one constructor per line with its location.
+func (ch Chain) Trace() string {
+	var sb strings.Builder
+	for i, frame := range ch {
+		if i > 0 {
+			sb.WriteString("\n  -> ")
+		}
+
+		fmt.Fprintf(&sb, "%s (%v) at %s:%d", frame, frame.Type, frame.File, frame.Line)
+	}
+
+	return sb.String()
+}
+
+// MissingDependencyError is returned when no provider or argument exists for a requested type.
+type MissingDependencyError struct {
+	Type  reflect.Type
+	Name  string
+	Chain Chain
+}
+
+func (e *MissingDependencyError) Error() string {
+	if len(e.Chain) == 0 {
+		return fmt.Sprintf("missing dependency %s", providerKey(e.Type, e.Name))
+	}
+
+	return fmt.Sprintf("%s (missing %s)", e.Chain, providerKey(e.Type, e.Name))
+}
+
+// Trace renders the resolution chain that led to the missing dependency on several lines.
+func (e *MissingDependencyError) Trace() string {
+	return fmt.Sprintf("%s\n  -> missing %s", e.Chain.Trace(), providerKey(e.Type, e.Name))
+}
+
+// CycleError is returned when a constructor transitively depends on itself.
+// The chain starts and ends with the same constructor.
+type CycleError struct {
+	Chain Chain
+}
+
+func (e *CycleError) Error() string {
+	return fmt.Sprintf("circular dependency detected: %s", e.Chain)
+}
+
+// Trace renders the cycle on several lines.
+func (e *CycleError) Trace() string {
+	return e.Chain.Trace()
+}
+
+// ConstructorError wraps an error returned by a constructor.
+// The last frame of the chain is the failing constructor.
+type ConstructorError struct {
+	Chain Chain
+	Err   error
+}
+
+func (e *ConstructorError) Error() string {
+	return fmt.Sprintf("%s: %v", e.Chain, e.Err)
+}
+
+func (e *ConstructorError) Unwrap() error {
+	return e.Err
+}
+
+// Trace renders the chain leading to the failing constructor on several lines.
+func (e *ConstructorError) Trace() string {
+	return fmt.Sprintf("%s\n  failed: %v", e.Chain.Trace(), e.Err)
+}
+
+// ServiceError is returned by App when a service fails to start or stop.
+// Service identifies the provider that built the service.
+type ServiceError struct {
+	Op      string
+	Service Frame
+	Err     error
+}
+
+func (e *ServiceError) Error() string {
+	return fmt.Sprintf("%s %s (%v): %v", e.Op, e.Service, e.Service.Type, e.Err)
+}
+
+func (e *ServiceError) Unwrap() error {
+	return e.Err
+}
+
+func newChain(providers []*Provider) Chain {
+	chain := make(Chain, 0, len(providers))
+	for _, prov := range providers {
+		chain = append(chain, prov.frame())
+	}
+
+	return chain
+}
+
+// wrapChain prefixes err with the resolution chain.
+func wrapChain(providers []*Provider, err error) error {
+	if len(providers) == 0 {
+		return err
+	}
+
+	return fmt.Errorf("%s: %w", newChain(providers), err)
+}
+
+func shortFuncName(name string) string {
+	if idx := strings.LastIndex(name, "/"); idx >= 0 {
+		return name[idx+1:]
+	}
+
+	return name
+}
@@ -0,0 +1,56 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+func TestMissingDependencyError(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(NewRepo)
+	c.Provide(NewMyService)
+	c.Provide(NewMyService2).Args(2, true)
+	c.Provide(NewRootService)
+
+	_, err := di.Resolve[RootSrv](c)
+
+	var missingErr *di.MissingDependencyError
+	require.ErrorAs(t, err, &missingErr)
+	require.Equal(t, "*di_test.MyServiceParams", missingErr.Type.String())
+	require.Len(t, missingErr.Chain, 2)
+	require.Equal(t, "di_test.NewRootService -> di_test.NewMyService (missing *di_test.MyServiceParams)", err.Error())
+	require.Contains(t, missingErr.Trace(), "di_test.go:")
+}
+
+func TestCycleError(t *testing.T) {
+	c := di.New()
+	c.Provide(newDep1)
+	c.Provide(newDep2)
+
+	_, err := di.Resolve[Dep1](c)
+
+	var cycleErr *di.CycleError
+	require.ErrorAs(t, err, &cycleErr)
+	require.Equal(t, "circular dependency detected: di_test.newDep1 -> di_test.newDep2 -> di_test.newDep1", err.Error())
+}
+
+func TestConstructorError(t *testing.T) {
+	errBoom := errors.New("boom")
+
+	c := di.New()
+	c.Provide(func() (*DBClientImpl, error) { return nil, errBoom })
+	c.Provide(NewRepo)
+
+	_, err := di.Resolve[Repo](c)
+
+	var ctorErr *di.ConstructorError
+	require.ErrorAs(t, err, &ctorErr)
+	require.ErrorIs(t, err, errBoom)
+	require.Len(t, ctorErr.Chain, 2)
+	require.Equal(t, "di_test.NewRepo", ctorErr.Chain[0].String())
+}
@@ -0,0 +1,6 @@
+package di
+
+// SetExit replaces os.Exit, which RunWithSignals calls on a second shutdown signal.
+func SetExit(app *App, exit func(code int)) {
+	app.exit = exit
+}
@@ -0,0 +1,75 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// ProvideFunc registers a constructor whose first result is assignable to T.
+// The provider is registered under T, so a constructor returning a concrete
+// type may be exposed as an interface: ProvideFunc[Repo](c, NewRepo).
+// Go generics cannot describe a function of any arity returning T, so only T is
+// checked at compile time: a constructor that is not a function or whose result
+// is not assignable to T panics here, at registration.
+func ProvideFunc[T any](c *Container, constructor any) *Provider {
+	prvdr := newProvider(constructor)
+
+	typ := reflect.TypeFor[T]()
+	if !prvdr.returnType.AssignableTo(typ) {
+		panic(fmt.Errorf("constructor %s returns %v, not assignable to %v", prvdr.name, prvdr.returnType, typ))
+	}
+
+	prvdr.returnType = typ
+	c.register(prvdr)
+
+	return prvdr
+}
+
+// ProvideValue registers an already built value of type T.
+func ProvideValue[T any](c *Container, value T) *Provider {
+	return c.Provide(func() T { return value })
+}
+
+// Resolve resolves an instance of type T from the container.
+func Resolve[T any](c *Container) (T, error) {
+	var target T
+	if err := c.Resolve(&target); err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	return target, nil
+}
+
+// MustResolve is like Resolve but panics if the instance cannot be resolved.
+func MustResolve[T any](c *Container) T {
+	target, err := Resolve[T](c)
+	if err != nil {
+		panic(err)
+	}
+
+	return target
+}
+
+// Named resolves the instance of type T registered with Provider.Named(name).
+func Named[T any](c *Container, name string) (T, error) {
+	var target T
+	if err := c.ResolveNamed(&target, name); err != nil {
+		var zero T
+
+		return zero, err
+	}
+
+	return target, nil
+}
+
+// ResolveGroup resolves all members of the group that are assignable to T.
+func ResolveGroup[T any](c *Container, group string) ([]T, error) {
+	var target []T
+	if err := c.ResolveGroup(&target, group); err != nil {
+		return nil, err
+	}
+
+	return target, nil
+}
@@ -0,0 +1,57 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+func TestResolve_Generic(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(NewRepo)
+
+	repo, err := di.Resolve[Repo](c)
+	require.NoError(t, err)
+
+	data, err := repo.Find()
+	require.NoError(t, err)
+	require.Equal(t, "data", data)
+
+	impl, err := di.Resolve[*RepoImpl](c)
+	require.NoError(t, err)
+	require.NotNil(t, impl)
+
+	_, err = di.Resolve[Service](c)
+	require.Error(t, err)
+}
+
+func TestMustResolve_Panics(t *testing.T) {
+	c := di.New()
+
+	require.Panics(t, func() { di.MustResolve[Repo](c) })
+}
+
+func TestProvideFunc(t *testing.T) {
+	c := di.New()
+	di.ProvideFunc[DBClient](c, NewDBClient).Arg("data")
+	di.ProvideFunc[Repo](c, NewRepo)
+
+	repo := di.MustResolve[Repo](c)
+	data, err := repo.Find()
+	require.NoError(t, err)
+	require.Equal(t, "data", data)
+
+	require.Panics(t, func() { di.ProvideFunc[Service](c, NewRepo) })
+}
+
+func TestProvideValue(t *testing.T) {
+	c := di.New()
+	params := &MyServiceParams{ParamInt: 1}
+	di.ProvideValue(c, params)
+
+	actual := di.MustResolve[*MyServiceParams](c)
+	require.Same(t, params, actual)
+}
@@ -0,0 +1,359 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"slices"
+	"strings"
+)
+
+// NodeKind tells what a graph node stands for.
+type NodeKind int
+
+const (
+	// NodeProvider is a registered constructor.
+	NodeProvider NodeKind = iota
+	// NodeArg is a value supplied with Provider.Arg or Provider.Args.
+	NodeArg
+	// NodeMissing is a dependency that cannot be satisfied.
+	NodeMissing
+	// NodeSupplied is a value registered with Container.Supply.
+	NodeSupplied
+	// NodeAmbiguous is a dependency matched by several providers, none of them preferred.
+	NodeAmbiguous
+)
+
+// GraphNode is a provider, an argument or a missing or ambiguous dependency.
+type GraphNode struct {
+	ID           string
+	Kind         NodeKind
+	Constructor  string
+	Type         reflect.Type
+	Name         string
+	Group        string
+	Module       string
+	Lifetime     Lifetime
+	Instantiated bool
+	Servicer     bool
+}
+
+// Label returns a short human-readable description of the node.
+func (n *GraphNode) Label() string {
+	switch n.Kind {
+	case NodeArg:
+		return fmt.Sprintf("arg %v", n.Type)
+	case NodeMissing:
+		return fmt.Sprintf("missing %v", n.Type)
+	case NodeAmbiguous:
+		return fmt.Sprintf("ambiguous %v", n.Type)
+	}
+
+	label := fmt.Sprintf("%s\n%v", shortFuncName(n.Constructor), n.Type)
+	if n.Kind == NodeSupplied {
+		label = fmt.Sprintf("supplied\n%v", n.Type)
+	}
+
+	if n.Name != "" {
+		label += fmt.Sprintf(" (%s)", n.Name)
+	}
+
+	return label
+}
+
+// GraphEdge points from a constructor to one of its dependencies.
+// Param is the constructor parameter type. It differs from the type of the dependency
+// node when an interface parameter is resolved to an implementation.
+type GraphEdge struct {
+	From  string
+	To    string
+	Param reflect.Type
+	// Deferred is set for Lazy[T] and func() (T, error) parameters.
+	Deferred bool
+}
+
+// ViaInterface reports whether the edge resolves an interface parameter to another type.
+func (e GraphEdge) ViaInterface(g *Graph) bool {
+	to := g.Node(e.To)
+
+	return to != nil && (to.Kind == NodeProvider || to.Kind == NodeSupplied) && e.Param.Kind() == reflect.Interface && to.Type != e.Param
+}
+
+// Graph is a snapshot of the providers registered in a container and their dependencies.
+type Graph struct {
+	Nodes []*GraphNode
+	Edges []GraphEdge
+}
+
+// Node returns the node with the given ID or nil.
+func (g *Graph) Node(id string) *GraphNode {
+	for _, node := range g.Nodes {
+		if node.ID == id {
+			return node
+		}
+	}
+
+	return nil
+}
+
+// Graph builds the dependency graph of the container without constructing anything.
+func (c *Container) Graph() *Graph {
+	root := c.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	g := &Graph{}
+	ids := make(map[*Provider]string, len(root.providers))
+	for i, prov := range root.providers {
+		kind := NodeProvider
+		if prov.supplied {
+			kind = NodeSupplied
+		}
+
+		node := &GraphNode{
+			ID:          fmt.Sprintf("p%d", i),
+			Kind:        kind,
+			Constructor: prov.name,
+			Type:        prov.returnType,
+			Name:        prov.named,
+			Group:       prov.group,
+			Module:      prov.moduleName(),
+			Lifetime:    prov.lifetime,
+			Servicer:    prov.returnType.Implements(servicerType),
+		}
+
+		if inst, ok := root.instances[instanceKey{provider: prov, typ: prov.returnType}]; ok {
+			node.Instantiated = true
+			if _, ok := inst.Interface().(Servicer); ok {
+				node.Servicer = true
+			}
+		}
+
+		ids[prov] = node.ID
+		g.Nodes = append(g.Nodes, node)
+	}
+
+	for _, prov := range root.providers {
+		for i, dep := range staticDependencies(root.providers, prov) {
+			from := ids[prov]
+			module := prov.moduleName()
+
+			switch {
+			case dep.arg:
+				node := &GraphNode{ID: fmt.Sprintf("%s_a%d", from, i), Kind: NodeArg, Type: dep.paramType, Module: module}
+				g.Nodes = append(g.Nodes, node)
+				g.Edges = append(g.Edges, GraphEdge{From: from, To: node.ID, Param: dep.paramType})
+			case dep.err != nil:
+				kind := NodeAmbiguous
+				var missing *MissingDependencyError
+				if errors.As(dep.err, &missing) {
+					kind = NodeMissing
+				}
+
+				node := &GraphNode{ID: fmt.Sprintf("%s_m%d", from, i), Kind: kind, Type: dep.paramType, Module: module}
+				g.Nodes = append(g.Nodes, node)
+				g.Edges = append(g.Edges, GraphEdge{From: from, To: node.ID, Param: dep.paramType})
+			default:
+				for _, depProv := range dep.providers {
+					g.Edges = append(g.Edges, GraphEdge{
+						From:     from,
+						To:       ids[depProv],
+						Param:    dep.paramType,
+						Deferred: dep.deferred,
+					})
+				}
+			}
+		}
+	}
+
+	return g
+}
+
+// Modules returns the names of the modules that own nodes, in order of appearance.
+// Nodes outside of any module are listed under the empty name first.
+func (g *Graph) Modules() []string {
+	modules := []string{""}
+	for _, node := range g.Nodes {
+		if !slices.Contains(modules, node.Module) {
+			modules = append(modules, node.Module)
+		}
+	}
+
+	return modules
+}
+
+// WriteDOT writes the graph in Graphviz DOT format. Never instantiated providers are
+// dashed, Servicer implementations are filled, arguments are notes and missing
+// dependencies are red, ambiguous ones orange. Edges resolving an interface are labelled
+// with it and modules are drawn as clusters.
+func (g *Graph) WriteDOT(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("digraph di {\n")
+	sb.WriteString("  rankdir=LR;\n")
+	sb.WriteString("  node [shape=box];\n")
+
+	for i, module := range g.Modules() {
+		indent := "  "
+		if module != "" {
+			fmt.Fprintf(&sb, "  subgraph cluster_%d {\n    label=%s;\n", i, dotQuote(module))
+			indent = "    "
+		}
+
+		for _, node := range g.Nodes {
+			if node.Module == module {
+				sb.WriteString(indent + dotNode(node))
+			}
+		}
+
+		if module != "" {
+			sb.WriteString("  }\n")
+		}
+	}
+
+	for _, edge := range g.Edges {
+		var attrs []string
+		if edge.ViaInterface(g) {
+			attrs = append(attrs, "label="+dotQuote(edge.Param.String()))
+		}
+		if edge.Deferred {
+			attrs = append(attrs, "style=dashed")
+		}
+
+		if len(attrs) > 0 {
+			fmt.Fprintf(&sb, "  %s -> %s [%s];\n", edge.From, edge.To, strings.Join(attrs, ", "))
+
+			continue
+		}
+
+		fmt.Fprintf(&sb, "  %s -> %s;\n", edge.From, edge.To)
+	}
+
+	sb.WriteString("}\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+func dotNode(node *GraphNode) string {
+	attrs := []string{"label=" + dotQuote(node.Label())}
+
+	switch node.Kind {
+	case NodeArg:
+		attrs = append(attrs, "shape=note")
+	case NodeMissing:
+		attrs = append(attrs, "color=red", "fontcolor=red")
+	case NodeAmbiguous:
+		attrs = append(attrs, "color=orange", "fontcolor=orange")
+	case NodeProvider, NodeSupplied:
+		var styles []string
+		if node.Kind == NodeSupplied {
+			styles = append(styles, "rounded")
+		}
+		if !node.Instantiated {
+			styles = append(styles, "dashed")
+		}
+		if node.Servicer {
+			styles = append(styles, "filled")
+			attrs = append(attrs, "fillcolor=lightblue")
+		}
+		if len(styles) > 0 {
+			attrs = append(attrs, "style="+dotQuote(strings.Join(styles, ",")))
+		}
+	}
+
+	return fmt.Sprintf("%s [%s];\n", node.ID, strings.Join(attrs, ", "))
+}
+
+// WriteMermaid writes the graph as a Mermaid flowchart using the same conventions as WriteDOT.
+func (g *Graph) WriteMermaid(w io.Writer) error {
+	var sb strings.Builder
+	sb.WriteString("flowchart LR\n")
+
+	for i, module := range g.Modules() {
+		indent := "  "
+		if module != "" {
+			fmt.Fprintf(&sb, "  subgraph m%d [%s]\n", i, mermaidQuote(module))
+			indent = "    "
+		}
+
+		for _, node := range g.Nodes {
+			if node.Module == module {
+				sb.WriteString(indent + mermaidNode(node))
+			}
+		}
+
+		if module != "" {
+			sb.WriteString("  end\n")
+		}
+	}
+
+	for _, node := range g.Nodes {
+		if node.Servicer {
+			fmt.Fprintf(&sb, "  class %s servicer\n", node.ID)
+		}
+	}
+
+	for _, edge := range g.Edges {
+		arrow := "-->"
+		if edge.Deferred {
+			arrow = "-.->"
+		}
+
+		if edge.ViaInterface(g) {
+			fmt.Fprintf(&sb, "  %s %s|%s| %s\n", edge.From, arrow, mermaidQuote(edge.Param.String()), edge.To)
+
+			continue
+		}
+
+		fmt.Fprintf(&sb, "  %s %s %s\n", edge.From, arrow, edge.To)
+	}
+
+	sb.WriteString("  classDef unused stroke-dasharray: 5 5\n")
+	sb.WriteString("  classDef servicer fill:#add8e6\n")
+	sb.WriteString("  classDef missing stroke:#f00,color:#f00\n")
+	sb.WriteString("  classDef ambiguous stroke:#f80,color:#f80\n")
+
+	_, err := io.WriteString(w, sb.String())
+
+	return err
+}
+
+func mermaidNode(node *GraphNode) string {
+	label := mermaidQuote(node.Label())
+
+	switch node.Kind {
+	case NodeArg:
+		return fmt.Sprintf("%s[/%s/]\n", node.ID, label)
+	case NodeMissing:
+		return fmt.Sprintf("%s{{%s}}:::missing\n", node.ID, label)
+	case NodeAmbiguous:
+		return fmt.Sprintf("%s{{%s}}:::ambiguous\n", node.ID, label)
+	case NodeSupplied:
+		return fmt.Sprintf("%s([%s])\n", node.ID, label)
+	}
+
+	if !node.Instantiated {
+		return fmt.Sprintf("%s[%s]:::unused\n", node.ID, label)
+	}
+
+	return fmt.Sprintf("%s[%s]\n", node.ID, label)
+}
+
+var servicerType = reflect.TypeFor[Servicer]()
+
+func dotQuote(s string) string {
+	s = strings.ReplaceAll(s, `\`, `\\`)
+	s = strings.ReplaceAll(s, `"`, `\"`)
+	s = strings.ReplaceAll(s, "\n", `\n`)
+
+	return `"` + s + `"`
+}
+
+func mermaidQuote(s string) string {
+	s = strings.ReplaceAll(s, `"`, "#quot;")
+	s = strings.ReplaceAll(s, "\n", "<br/>")
+
+	return `"` + s + `"`
+}
@@ -0,0 +1,92 @@
+package di_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+func TestContainer_Graph(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(NewRepo)
+	c.Provide(NewMyService)
+
+	_, err := di.Resolve[Repo](c)
+	require.NoError(t, err)
+
+	g := c.Graph()
+	require.Len(t, g.Nodes, 5)
+
+	dbNode := g.Node("p0")
+	require.Equal(t, di.NodeProvider, dbNode.Kind)
+	require.True(t, dbNode.Instantiated)
+	require.False(t, g.Node("p2").Instantiated)
+	require.Equal(t, di.NodeArg, g.Node("p0_a0").Kind)
+	require.Equal(t, di.NodeMissing, g.Node("p2_m0").Kind)
+
+	repoEdge := g.Edges[1]
+	require.Equal(t, "p1", repoEdge.From)
+	require.Equal(t, "p0", repoEdge.To)
+	require.Equal(t, "di_test.DBClient", repoEdge.Param.String())
+	require.True(t, repoEdge.ViaInterface(g))
+}
+
+func TestGraph_WriteDOT(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(NewRepo)
+
+	var sb strings.Builder
+	require.NoError(t, c.Graph().WriteDOT(&sb))
+
+	expected := `digraph di {
+  rankdir=LR;
+  node [shape=box];
+  p0 [label="di_test.NewDBClient\n*di_test.DBClientImpl", style="dashed"];
+  p1 [label="di_test.NewRepo\n*di_test.RepoImpl", style="dashed"];
+  p0_a0 [label="arg string", shape=note];
+  p0 -> p0_a0;
+  p1 -> p0 [label="di_test.DBClient"];
+}
+`
+	require.Equal(t, expected, sb.String())
+}
+
+func TestGraph_WriteMermaid(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(NewRepo)
+	c.Provide(func() AppService1 { return &MockAppService1{} })
+	_, err := di.Resolve[AppService1](c)
+	require.NoError(t, err)
+
+	var sb strings.Builder
+	require.NoError(t, c.Graph().WriteMermaid(&sb))
+
+	out := sb.String()
+	require.True(t, strings.HasPrefix(out, "flowchart LR\n"))
+	require.Contains(t, out, `p1 -->|"di_test.DBClient"| p0`)
+	require.Contains(t, out, `p0_a0[/"arg string"/]`)
+	require.Contains(t, out, "class p2 servicer")
+	require.Contains(t, out, `p0["di_test.NewDBClient<br/>*di_test.DBClientImpl"]:::unused`)
+}
+
+func TestContainer_GraphAmbiguous(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(func() *MockDBClient { return &MockDBClient{} })
+	c.Provide(NewRepo)
+
+	g := c.Graph()
+	node := g.Node("p2_m0")
+	require.Equal(t, di.NodeAmbiguous, node.Kind)
+	require.Equal(t, "ambiguous di_test.DBClient", node.Label())
+
+	var sb strings.Builder
+	require.NoError(t, g.WriteMermaid(&sb))
+	require.Contains(t, sb.String(), `p2_m0{{"ambiguous di_test.DBClient"}}:::ambiguous`)
+}
@@ -0,0 +1,194 @@
+package di
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HealthChecker is implemented by components that can report whether they are alive.
+type HealthChecker interface {
+	Health(ctx context.Context) error
+}
+
+// ReadinessChecker is implemented by components that can report whether they are ready
+// to serve traffic, e.g. after warming a cache.
+type ReadinessChecker interface {
+	Ready(ctx context.Context) error
+}
+
+// State is the lifecycle state of an App.
+type State int32
+
+const (
+	StateCreated State = iota
+	StateStarting
+	StateRunning
+	StateStopping
+	StateStopped
+)
+
+func (s State) String() string {
+	switch s {
+	case StateCreated:
+		return "created"
+	case StateStarting:
+		return "starting"
+	case StateRunning:
+		return "running"
+	case StateStopping:
+		return "stopping"
+	case StateStopped:
+		return "stopped"
+	default:
+		return "unknown"
+	}
+}
+
+func (s State) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+const (
+	StatusUp   = "up"
+	StatusDown = "down"
+)
+
+// HealthReport is the result of App.Health and App.Ready.
+type HealthReport struct {
+	Status     string            `json:"status"`
+	State      State             `json:"state"`
+	Components []ComponentHealth `json:"components"`
+}
+
+// ComponentHealth is the result of a single check.
+type ComponentHealth struct {
+	Name    string        `json:"name"`
+	Type    string        `json:"type"`
+	Status  string        `json:"status"`
+	Latency time.Duration `json:"-"`
+	Error   string        `json:"error,omitempty"`
+}
+
+func (h ComponentHealth) MarshalJSON() ([]byte, error) {
+	type component ComponentHealth
+
+	return json.Marshal(struct {
+		component
+		Latency string `json:"latency"`
+	}{component: component(h), Latency: h.Latency.String()})
+}
+
+// State returns the current lifecycle state.
+func (app *App) State() State {
+	return State(app.state.Load())
+}
+
+// Health runs the checks of all HealthChecker instances concurrently. The report is up
+// if every check passes, whatever the lifecycle state.
+func (app *App) Health(ctx context.Context) HealthReport {
+	return app.check(ctx, func(value any) func(context.Context) error {
+		if checker, ok := value.(HealthChecker); ok {
+			return checker.Health
+		}
+
+		return nil
+	}, false)
+}
+
+// Ready runs the checks of all ReadinessChecker instances concurrently. The report is up
+// only while the application is running and every check passes.
+func (app *App) Ready(ctx context.Context) HealthReport {
+	return app.check(ctx, func(value any) func(context.Context) error {
+		if checker, ok := value.(ReadinessChecker); ok {
+			return checker.Ready
+		}
+
+		return nil
+	}, true)
+}
+
+func (app *App) check(ctx context.Context, checkOf func(any) func(context.Context) error, needRunning bool) HealthReport {
+	app.container.mu.Lock()
+	entries := append([]instanceEntry(nil), app.container.instancesList...)
+	app.container.mu.Unlock()
+
+	report := HealthReport{Status: StatusUp, State: app.State(), Components: []ComponentHealth{}}
+	if needRunning && report.State != StateRunning {
+		report.Status = StatusDown
+	}
+
+	var checks []func(context.Context) error
+	for _, entry := range entries {
+		check := checkOf(entry.value)
+		if check == nil {
+			continue
+		}
+
+		frame := entry.provider.frame()
+		checks = append(checks, check)
+		report.Components = append(report.Components, ComponentHealth{
+			Name: frame.String(),
+			Type: frame.Type.String(),
+		})
+	}
+
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			component := &report.Components[i]
+			started := time.Now()
+			err := withTimeout(ctx, check)
+			component.Latency = time.Since(started)
+			component.Status = StatusUp
+			if err != nil {
+				component.Status = StatusDown
+				component.Error = err.Error()
+			}
+		}()
+	}
+
+	wg.Wait()
+
+	for _, component := range report.Components {
+		if component.Status == StatusDown {
+			report.Status = StatusDown
+		}
+	}
+
+	return report
+}
+
+// HealthHandler returns an http.Handler serving the Health report on paths ending with
+// /healthz and the Ready report on paths ending with /readyz, as JSON with status 200 when
+// up and 503 when down.
+func (app *App) HealthHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var report HealthReport
+		switch {
+		case strings.HasSuffix(r.URL.Path, "/healthz"):
+			report = app.Health(r.Context())
+		case strings.HasSuffix(r.URL.Path, "/readyz"):
+			report = app.Ready(r.Context())
+		default:
+			http.NotFound(w, r)
+
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		if report.Status != StatusUp {
+			w.WriteHeader(http.StatusServiceUnavailable)
+		} else {
+			w.WriteHeader(http.StatusOK)
+		}
+
+		_ = json.NewEncoder(w).Encode(report)
+	})
+}
@@ -0,0 +1,106 @@
+package di_test
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+type checkedDB struct {
+	healthErr error
+	readyErr  error
+}
+
+func (db *checkedDB) Health(context.Context) error {
+	return db.healthErr
+}
+
+func (db *checkedDB) Ready(context.Context) error {
+	return db.readyErr
+}
+
+func NewCheckedDB() *checkedDB {
+	return &checkedDB{}
+}
+
+func TestApp_Health(t *testing.T) {
+	c := di.New()
+	c.Provide(NewCheckedDB)
+	db := di.MustResolve[*checkedDB](c)
+
+	app := di.NewApp(c)
+	require.Equal(t, di.StateCreated, app.State())
+
+	report := app.Health(context.Background())
+	require.Equal(t, di.StatusUp, report.Status)
+	require.Len(t, report.Components, 1)
+	require.Equal(t, "*di_test.checkedDB", report.Components[0].Type)
+	require.Equal(t, di.StatusUp, report.Components[0].Status)
+
+	// Not ready before the application is running.
+	require.Equal(t, di.StatusDown, app.Ready(context.Background()).Status)
+
+	require.NoError(t, app.Start(context.Background()))
+	require.Equal(t, di.StateRunning, app.State())
+	require.Equal(t, di.StatusUp, app.Ready(context.Background()).Status)
+
+	db.readyErr = errors.New("warming up")
+	report = app.Ready(context.Background())
+	require.Equal(t, di.StatusDown, report.Status)
+	require.Equal(t, "warming up", report.Components[0].Error)
+
+	require.NoError(t, app.Stop(context.Background()))
+	require.Equal(t, di.StateStopped, app.State())
+}
+
+func TestApp_HealthHandler(t *testing.T) {
+	c := di.New()
+	c.Provide(NewCheckedDB)
+	db := di.MustResolve[*checkedDB](c)
+
+	app := di.NewApp(c)
+	require.NoError(t, app.Start(context.Background()))
+
+	srv := httptest.NewServer(app.HealthHandler())
+	defer srv.Close()
+
+	get := func(path string) (int, map[string]any) {
+		resp, err := http.Get(srv.URL + path)
+		require.NoError(t, err)
+		defer resp.Body.Close()
+
+		var body map[string]any
+		require.NoError(t, json.NewDecoder(resp.Body).Decode(&body))
+
+		return resp.StatusCode, body
+	}
+
+	code, body := get("/healthz")
+	require.Equal(t, http.StatusOK, code)
+	require.Equal(t, "up", body["status"])
+	require.Equal(t, "running", body["state"])
+
+	component := body["components"].([]any)[0].(map[string]any)
+	require.Contains(t, component, "latency")
+	require.NotContains(t, component, "error")
+
+	db.healthErr = errors.New("connection lost")
+	code, body = get("/healthz")
+	require.Equal(t, http.StatusServiceUnavailable, code)
+	require.Equal(t, "down", body["status"])
+
+	code, _ = get("/readyz")
+	require.Equal(t, http.StatusOK, code)
+
+	resp, err := http.Get(srv.URL + "/other")
+	require.NoError(t, err)
+	resp.Body.Close()
+	require.Equal(t, http.StatusNotFound, resp.StatusCode)
+}
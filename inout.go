@@ -0,0 +1,140 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// In is embedded into a parameter struct to have its exported fields injected individually:
+//
+//	type ServerParams struct {
+//		di.In
+//
+//		DB      *sql.DB
+//		Replica *sql.DB  `di:"name=replica"`
+//		Tracer  *Tracer  `di:"optional"`
+//		Routes  []Route  `di:"group=routes"`
+//	}
+//
+//	func NewServer(p ServerParams) *Server
+type In struct{}
+
+// Out is embedded into a result struct to register each of its exported fields as a
+// separate provider. Fields accept the name and group options of the di tag.
+type Out struct{}
+
+var (
+	inType  = reflect.TypeFor[In]()
+	outType = reflect.TypeFor[Out]()
+)
+
+// structField is an exported field of an In or Out struct with its parsed tag.
+type structField struct {
+	name  string
+	index int
+	typ   reflect.Type
+	tag   fieldTag
+}
+
+func embeds(t, marker reflect.Type) bool {
+	if t.Kind() != reflect.Struct {
+		return false
+	}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if field.Anonymous && field.Type == marker {
+			return true
+		}
+	}
+
+	return false
+}
+
+// markedFields returns the exported fields of t except the embedded marker.
+func markedFields(t, marker reflect.Type) ([]structField, error) {
+	var fields []structField
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if (field.Anonymous && field.Type == marker) || !field.IsExported() {
+			continue
+		}
+
+		tag, err := parseTag(field.Tag.Get(tagName))
+		if err != nil {
+			return nil, fmt.Errorf("field %q of %v: %w", field.Name, t, err)
+		}
+
+		if tag.skip {
+			continue
+		}
+
+		if tag.group != "" && field.Type.Kind() != reflect.Slice && marker == inType {
+			return nil, fmt.Errorf("field %q of %v: group field must be a slice", field.Name, t)
+		}
+
+		fields = append(fields, structField{name: field.Name, index: i, typ: field.Type, tag: tag})
+	}
+
+	return fields, nil
+}
+
+// newOutProviders returns a provider for every exported field of the Out struct built by parent.
+// Field providers are bound to parent itself, whatever name it is registered under.
+func newOutProviders(parent *Provider) []*Provider {
+	fields, err := markedFields(parent.returnType, outType)
+	if err != nil {
+		panic(err)
+	}
+
+	providers := make([]*Provider, 0, len(fields))
+	for _, field := range fields {
+		index := field.index
+		providers = append(providers, &Provider{
+			name:       parent.name + "." + field.name,
+			file:       parent.file,
+			line:       parent.line,
+			returnType: field.typ,
+			paramTypes: []reflect.Type{parent.returnType},
+			initFunc: func(args []reflect.Value) (any, error) {
+				return args[0].Field(index).Interface(), nil
+			},
+			named:    field.tag.name,
+			group:    field.tag.group,
+			module:   parent.module,
+			bindings: map[int]binding{0: {provider: parent}},
+			args:     make(map[reflect.Type]reflect.Value),
+		})
+	}
+
+	return providers
+}
+
+// buildIn fills a parameter struct embedding In.
+func (c *Container) buildIn(t reflect.Type) (reflect.Value, error) {
+	fields, err := markedFields(t, inType)
+	if err != nil {
+		return reflect.Value{}, err
+	}
+
+	result := reflect.New(t).Elem()
+	for _, field := range fields {
+		inst, err := c.resolveField(field.typ, field.tag)
+		if err != nil {
+			return reflect.Value{}, fmt.Errorf("field %q of %v: %w", field.name, t, err)
+		}
+
+		result.Field(field.index).Set(inst)
+	}
+
+	return result, nil
+}
+
+// resolveField resolves a value described by a di tag.
+func (c *Container) resolveField(t reflect.Type, tag fieldTag) (reflect.Value, error) {
+	if tag.group != "" {
+		return c.getGroup(t, tag.group)
+	}
+
+	return c.resolveParam(t, tag.name, tag.optional)
+}
@@ -0,0 +1,135 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+type ServerParams struct {
+	di.In
+
+	DB         DBClient
+	Replica    *DBClientImpl `di:"name=replica"`
+	Tracer     *Tracer       `di:"optional"`
+	Migrations []Migration   `di:"group=migrations"`
+
+	internal string
+}
+
+type Server struct {
+	params ServerParams
+}
+
+func NewServer(p ServerParams) *Server {
+	return &Server{params: p}
+}
+
+func TestIn(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("primary")
+	c.Provide(func() *DBClientImpl { return NewDBClient("replica") }).Named("replica")
+	c.Provide(func() migration { return 1 }).Group("migrations")
+	c.Provide(func() Migration { return migration(2) })
+	c.Provide(NewServer)
+
+	require.NoError(t, c.Validate())
+
+	srv, err := di.Resolve[*Server](c)
+	require.NoError(t, err)
+
+	data, _ := srv.params.DB.Exec()
+	require.Equal(t, "primary", data)
+	require.Equal(t, "replica", srv.params.Replica.data)
+	require.Nil(t, srv.params.Tracer)
+	require.Len(t, srv.params.Migrations, 1)
+}
+
+func TestIn_MissingField(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("primary")
+	c.Provide(NewServer)
+
+	require.ErrorContains(t, c.Validate(), `missing *di_test.DBClientImpl named "replica"`)
+
+	_, err := di.Resolve[*Server](c)
+
+	var missingErr *di.MissingDependencyError
+	require.ErrorAs(t, err, &missingErr)
+	require.Equal(t, "replica", missingErr.Name)
+}
+
+func TestIn_InvalidTag(t *testing.T) {
+	type BadParams struct {
+		di.In
+
+		DB DBClient `di:"unknown"`
+	}
+
+	c := di.New()
+	require.Panics(t, func() { c.Provide(func(BadParams) *Server { return nil }) })
+}
+
+type Clients struct {
+	di.Out
+
+	Primary *DBClientImpl
+	Replica *DBClientImpl `di:"name=replica"`
+	Repo    Repo          `di:"group=repos"`
+}
+
+func NewClients() Clients {
+	primary := NewDBClient("primary")
+
+	return Clients{
+		Primary: primary,
+		Replica: NewDBClient("replica"),
+		Repo:    NewRepo(primary),
+	}
+}
+
+func TestOut(t *testing.T) {
+	c := di.New()
+	c.Provide(NewClients)
+
+	require.NoError(t, c.Validate())
+
+	primary, err := di.Resolve[*DBClientImpl](c)
+	require.NoError(t, err)
+	require.Equal(t, "primary", primary.data)
+
+	replica, err := di.Named[*DBClientImpl](c, "replica")
+	require.NoError(t, err)
+	require.Equal(t, "replica", replica.data)
+
+	repos, err := di.ResolveGroup[Repo](c, "repos")
+	require.NoError(t, err)
+	require.Len(t, repos, 1)
+	require.Same(t, primary, repos[0].(*RepoImpl).db)
+}
+
+func TestOut_Named(t *testing.T) {
+	c := di.New()
+	c.Provide(NewClients).Named("clients")
+
+	require.NoError(t, c.Validate())
+
+	primary, err := di.Resolve[*DBClientImpl](c)
+	require.NoError(t, err)
+	require.Equal(t, "primary", primary.data)
+
+	clients, err := di.Named[Clients](c, "clients")
+	require.NoError(t, err)
+	require.Same(t, primary, clients.Primary)
+}
+
+func TestOut_Lifetime(t *testing.T) {
+	c := di.New()
+	c.Provide(NewClients).Transient()
+
+	first := di.MustResolve[*DBClientImpl](c)
+	second := di.MustResolve[*DBClientImpl](c)
+	require.NotSame(t, first, second)
+}
@@ -0,0 +1,164 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// Lazy defers the resolution of a dependency until Get is first called, e.g.
+// func NewDispatcher(handlers di.Lazy[[]Handler]). It breaks construction cycles and
+// avoids building expensive dependencies that are never used.
+// Get returns ErrResolveInConstructor instead of deadlocking when it is called before the
+// resolution that injected it has finished, e.g. from inside a constructor.
+type Lazy[T any] struct {
+	state *lazyState
+}
+
+type lazyState struct {
+	once    sync.Once
+	resolve func() (reflect.Value, error)
+	pending *atomic.Bool
+	value   reflect.Value
+	err     error
+}
+
+var errLazyNotInitialized = errors.New("lazy dependency was not created by the container")
+
+// ErrResolveInConstructor is returned by Lazy.Get and injected factories called before the
+// resolution that injected them has finished, which would otherwise deadlock.
+var ErrResolveInConstructor = errors.New("deferred dependency resolved while a constructor is running")
+
+// Get resolves the dependency on first use and returns the same result afterwards.
+func (l Lazy[T]) Get() (T, error) {
+	var target T
+	if l.state == nil {
+		return target, errLazyNotInitialized
+	}
+
+	if l.state.pending.Load() {
+		return target, ErrResolveInConstructor
+	}
+
+	l.state.once.Do(func() {
+		l.state.value, l.state.err = l.state.resolve()
+	})
+
+	if l.state.err != nil {
+		return target, l.state.err
+	}
+
+	reflect.ValueOf(&target).Elem().Set(l.state.value)
+
+	return target, nil
+}
+
+// MustGet is like Get but panics if the dependency cannot be resolved.
+func (l Lazy[T]) MustGet() T {
+	target, err := l.Get()
+	if err != nil {
+		panic(err)
+	}
+
+	return target
+}
+
+func (Lazy[T]) lazyType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (l *Lazy[T]) setLazy(state *lazyState) {
+	l.state = state
+}
+
+type lazyParam interface {
+	lazyType() reflect.Type
+}
+
+type lazySetter interface {
+	setLazy(state *lazyState)
+}
+
+var (
+	lazyParamType = reflect.TypeFor[lazyParam]()
+	errorType     = reflect.TypeFor[error]()
+	contextType   = reflect.TypeFor[context.Context]()
+)
+
+// lazyElem returns T if t is Lazy[T].
+func lazyElem(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || !t.Implements(lazyParamType) {
+		return nil, false
+	}
+
+	return reflect.Zero(t).Interface().(lazyParam).lazyType(), true
+}
+
+// factoryElem returns T if t is func() (T, error).
+func factoryElem(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Func || t.NumIn() != 0 || t.NumOut() != 2 || t.Out(1) != errorType {
+		return nil, false
+	}
+
+	return t.Out(0), true
+}
+
+// deferredResolver returns a function resolving t later on behalf of the constructor
+// currently being built, with the same visibility of private providers. It fails while
+// the returned flag is set, i.e. until the current resolution releases the container.
+func (c *Container) deferredResolver(t reflect.Type, name string) (func() (reflect.Value, error), *atomic.Bool) {
+	requester := c.requester()
+
+	pending := new(atomic.Bool)
+	pending.Store(true)
+	c.pending = append(c.pending, pending)
+
+	return func() (reflect.Value, error) {
+		if pending.Load() {
+			return reflect.Value{}, ErrResolveInConstructor
+		}
+
+		c.mu.Lock()
+		defer c.unlock()
+
+		c.deferredFor = requester
+		defer func() { c.deferredFor = nil }()
+
+		return c.getInstanceByType(t, name)
+	}, pending
+}
+
+// unlock releases c.mu at the end of a resolution. Deferred dependencies created by the
+// resolution may be resolved from now on.
+func (c *Container) unlock() {
+	for _, pending := range c.pending {
+		pending.Store(false)
+	}
+	c.pending = nil
+
+	c.mu.Unlock()
+}
+
+func (c *Container) newLazy(t reflect.Type, elem reflect.Type, name string) reflect.Value {
+	resolve, pending := c.deferredResolver(elem, name)
+
+	lazy := reflect.New(t)
+	lazy.Interface().(lazySetter).setLazy(&lazyState{resolve: resolve, pending: pending})
+
+	return lazy.Elem()
+}
+
+func (c *Container) newFactory(t reflect.Type, elem reflect.Type, name string) reflect.Value {
+	resolve, _ := c.deferredResolver(elem, name)
+
+	return reflect.MakeFunc(t, func([]reflect.Value) []reflect.Value {
+		inst, err := resolve()
+		if err != nil {
+			return []reflect.Value{reflect.Zero(elem), reflect.ValueOf(&err).Elem()}
+		}
+
+		return []reflect.Value{inst, reflect.Zero(errorType)}
+	})
+}
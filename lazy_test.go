@@ -0,0 +1,179 @@
+package di_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+type EventHandler interface {
+	Handle(event string) string
+}
+
+type Dispatcher struct {
+	handlers di.Lazy[[]EventHandler]
+}
+
+func NewDispatcher(handlers di.Lazy[[]EventHandler]) *Dispatcher {
+	return &Dispatcher{handlers: handlers}
+}
+
+func (d *Dispatcher) Dispatch(event string) []string {
+	var results []string
+	for _, h := range d.handlers.MustGet() {
+		results = append(results, h.Handle(event))
+	}
+
+	return results
+}
+
+type echoHandler struct {
+	dispatcher *Dispatcher
+}
+
+func newEchoHandler(d *Dispatcher) *echoHandler {
+	return &echoHandler{dispatcher: d}
+}
+
+func (h *echoHandler) Handle(event string) string { return "echo " + event }
+
+func TestLazy_BreaksCycle(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDispatcher)
+	c.Provide(newEchoHandler)
+
+	require.NoError(t, c.Validate())
+
+	h := di.MustResolve[*echoHandler](c)
+	require.Equal(t, []string{"echo ping"}, h.dispatcher.Dispatch("ping"))
+	require.Same(t, h, h.dispatcher.handlers.MustGet()[0])
+}
+
+type ExpensiveClient struct {
+	id int
+}
+
+func TestLazy_DefersConstruction(t *testing.T) {
+	built := 0
+
+	c := di.New()
+	c.Provide(func() *ExpensiveClient {
+		built++
+
+		return &ExpensiveClient{}
+	})
+	c.Provide(func(cl di.Lazy[*ExpensiveClient]) di.Lazy[*ExpensiveClient] { return cl })
+
+	lazy := di.MustResolve[di.Lazy[*ExpensiveClient]](c)
+	require.Equal(t, 0, built)
+
+	cl1, err := lazy.Get()
+	require.NoError(t, err)
+	cl2, err := lazy.Get()
+	require.NoError(t, err)
+	require.Same(t, cl1, cl2)
+	require.Equal(t, 1, built)
+}
+
+func TestLazy_Missing(t *testing.T) {
+	c := di.New()
+	c.Provide(func(cl di.Lazy[*ExpensiveClient]) di.Lazy[*ExpensiveClient] { return cl })
+
+	require.Error(t, c.Validate())
+
+	lazy := di.MustResolve[di.Lazy[*ExpensiveClient]](c)
+	_, err := lazy.Get()
+	require.ErrorContains(t, err, "missing dependency *di_test.ExpensiveClient")
+
+	var zero di.Lazy[*ExpensiveClient]
+	_, err = zero.Get()
+	require.Error(t, err)
+}
+
+type clientFactory struct {
+	create func() (*ExpensiveClient, error)
+}
+
+func TestFactoryFunc(t *testing.T) {
+	c := di.New()
+	c.Provide(func() *ExpensiveClient { return &ExpensiveClient{} }).Transient()
+	c.Provide(func(create func() (*ExpensiveClient, error)) *clientFactory {
+		return &clientFactory{create: create}
+	})
+
+	f := di.MustResolve[*clientFactory](c)
+	cl1, err := f.create()
+	require.NoError(t, err)
+	cl2, err := f.create()
+	require.NoError(t, err)
+	require.NotSame(t, cl1, cl2)
+}
+
+func TestLazy_GetInsideConstructor(t *testing.T) {
+	c := di.New()
+	c.Provide(func() *ExpensiveClient { return &ExpensiveClient{id: 1} })
+	c.Provide(func(cl di.Lazy[*ExpensiveClient]) (*clientFactory, error) {
+		_, err := cl.Get()
+
+		return &clientFactory{}, err
+	})
+	c.Provide(func(create func() (*ExpensiveClient, error)) (*Dispatcher, error) {
+		_, err := create()
+
+		return &Dispatcher{}, err
+	})
+
+	_, err := di.Resolve[*clientFactory](c)
+	require.ErrorIs(t, err, di.ErrResolveInConstructor)
+
+	_, err = di.Resolve[*Dispatcher](c)
+	require.ErrorIs(t, err, di.ErrResolveInConstructor)
+}
+
+func TestLazy_GetWaitsForOtherResolution(t *testing.T) {
+	type lazyClient struct {
+		client di.Lazy[*ExpensiveClient]
+	}
+
+	entered := make(chan struct{})
+	release := make(chan struct{})
+
+	c := di.New()
+	c.Provide(func() *ExpensiveClient { return &ExpensiveClient{id: 1} })
+	c.Provide(func(cl di.Lazy[*ExpensiveClient]) *lazyClient { return &lazyClient{client: cl} })
+	c.Provide(func() *Dispatcher {
+		close(entered)
+		<-release
+
+		return &Dispatcher{}
+	})
+
+	holder, err := di.Resolve[*lazyClient](c)
+	require.NoError(t, err)
+
+	resolved := make(chan error, 1)
+	go func() {
+		_, err := di.Resolve[*Dispatcher](c)
+		resolved <- err
+	}()
+	<-entered
+
+	got := make(chan error, 1)
+	go func() {
+		_, err := holder.client.Get()
+		got <- err
+	}()
+
+	select {
+	case err := <-got:
+		t.Fatalf("Get returned while another goroutine was resolving: %v", err)
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	close(release)
+	require.NoError(t, <-resolved)
+	require.NoError(t, <-got)
+}
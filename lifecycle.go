@@ -0,0 +1,226 @@
+package di
+
+import (
+	"context"
+	"io"
+	"maps"
+	"slices"
+	"time"
+)
+
+// service is an instance with lifecycle behaviour together with the provider that built it.
+// start and stop are nil when there is nothing to run.
+type service struct {
+	provider *Provider
+	start    func(context.Context) error
+	stop     func(context.Context) error
+}
+
+// Shutdowner is implemented by components that stop with Shutdown, like *http.Server.
+type Shutdowner interface {
+	Shutdown(ctx context.Context) error
+}
+
+// newService collects the lifecycle of an instance: Servicer methods and the OnStart and
+// OnStop hooks of its provider. Start hooks run after Servicer.Start and stop hooks before
+// Servicer.Stop. Without a Servicer or stop hooks, Shutdowner and io.Closer are used to stop
+// the instance unless it was supplied, since the caller owns supplied values. It returns nil
+// if the instance has no lifecycle.
+func newService(entry instanceEntry) *service {
+	if entry.value == nil {
+		return nil
+	}
+
+	p := entry.provider
+
+	var start, stop []func(context.Context) error
+	servicer, isServicer := entry.value.(Servicer)
+	if isServicer {
+		start = append(start, servicer.Start)
+	}
+
+	for _, hook := range p.onStart {
+		start = append(start, bindHook(hook, entry.value))
+	}
+
+	for _, hook := range p.onStop {
+		stop = append(stop, bindHook(hook, entry.value))
+	}
+
+	switch v := entry.value.(type) {
+	case Servicer:
+		stop = append(stop, v.Stop)
+	case Shutdowner:
+		if len(stop) == 0 && !p.supplied {
+			stop = append(stop, v.Shutdown)
+		}
+	case io.Closer:
+		if len(stop) == 0 && !p.supplied {
+			stop = append(stop, func(context.Context) error { return v.Close() })
+		}
+	}
+
+	if len(start) == 0 && len(stop) == 0 {
+		return nil
+	}
+
+	return &service{provider: p, start: sequence(start), stop: sequence(stop)}
+}
+
+func bindHook(hook func(context.Context, any) error, inst any) func(context.Context) error {
+	return func(ctx context.Context) error {
+		return hook(ctx, inst)
+	}
+}
+
+// sequence runs fns one after another until the first error.
+func sequence(fns []func(context.Context) error) func(context.Context) error {
+	switch len(fns) {
+	case 0:
+		return nil
+	case 1:
+		return fns[0]
+	}
+
+	return func(ctx context.Context) error {
+		for _, fn := range fns {
+			if err := fn(ctx); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+}
+
+// serviceOp is a lifecycle operation App runs on every service.
+type serviceOp struct {
+	verb    string
+	fn      func(*service) func(context.Context) error
+	timeout func(*Provider) time.Duration
+	begin   func(Frame) Event
+	end     func(Frame, time.Duration, error) Event
+}
+
+var (
+	startOp = serviceOp{
+		verb:    "start",
+		fn:      func(s *service) func(context.Context) error { return s.start },
+		timeout: func(p *Provider) time.Duration { return p.startTimeout },
+		begin:   func(f Frame) Event { return ServiceStarting{Service: f} },
+		end: func(f Frame, d time.Duration, err error) Event {
+			return ServiceStarted{Service: f, Duration: d, Err: err}
+		},
+	}
+	stopOp = serviceOp{
+		verb:    "stop",
+		fn:      func(s *service) func(context.Context) error { return s.stop },
+		timeout: func(p *Provider) time.Duration { return p.stopTimeout },
+		begin:   func(f Frame) Event { return ServiceStopping{Service: f} },
+		end: func(f Frame, d time.Duration, err error) Event {
+			return ServiceStopped{Service: f, Duration: d, Err: err}
+		},
+	}
+)
+
+// callWithTimeout is withTimeout with an additional limit. Zero means no extra limit.
+// If the call is abandoned because ctx is done, its eventual result is passed to late,
+// unless late is nil.
+func callWithTimeout(ctx context.Context, timeout time.Duration, fn func(context.Context) error, late func(error)) error {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	ch := make(chan error, 1)
+	go func() {
+		ch <- fn(ctx)
+	}()
+
+	select {
+	case <-ctx.Done():
+		if late != nil {
+			go func() { late(<-ch) }()
+		}
+
+		return ctx.Err()
+	case err := <-ch:
+		return err
+	}
+}
+
+// serviceWaves groups the instances of the container that have a lifecycle into start waves. A service
+// is placed in the wave after the last service it depends on, directly or through instances
+// that are not services. Services within a wave keep construction order. Dependencies
+// resolved through Lazy or factory parameters are not taken into account.
+func (c *Container) serviceWaves() [][]*service {
+	c.mu.Lock()
+	entries := slices.Clone(c.instancesList)
+	deps := maps.Clone(c.dependencies)
+	c.mu.Unlock()
+
+	services := make(map[*Provider]*service)
+	ordered := make([]*service, 0, len(entries))
+	for _, entry := range entries {
+		if svc := newService(entry); svc != nil {
+			services[entry.provider] = svc
+			ordered = append(ordered, svc)
+		}
+	}
+
+	// serviceDeps returns the nearest services reachable from p.
+	serviceDeps := func(p *Provider) []*Provider {
+		var result []*Provider
+		visited := map[*Provider]bool{p: true}
+		stack := slices.Clone(deps[p])
+		for len(stack) > 0 {
+			dep := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			if visited[dep] {
+				continue
+			}
+
+			visited[dep] = true
+			if _, ok := services[dep]; ok {
+				result = append(result, dep)
+
+				continue
+			}
+
+			stack = append(stack, deps[dep]...)
+		}
+
+		return result
+	}
+
+	levels := make(map[*Provider]int)
+	var level func(p *Provider) int
+	level = func(p *Provider) int {
+		if l, ok := levels[p]; ok {
+			return l
+		}
+
+		levels[p] = 0
+		l := 0
+		for _, dep := range serviceDeps(p) {
+			l = max(l, level(dep)+1)
+		}
+
+		levels[p] = l
+
+		return l
+	}
+
+	var waves [][]*service
+	for _, svc := range ordered {
+		l := level(svc.provider)
+		for len(waves) <= l {
+			waves = append(waves, nil)
+		}
+
+		waves[l] = append(waves[l], svc)
+	}
+
+	return waves
+}
@@ -0,0 +1,347 @@
+package di_test
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+// lifecycleLog records lifecycle calls of services in order.
+type lifecycleLog struct {
+	mu    sync.Mutex
+	calls []string
+}
+
+func (l *lifecycleLog) add(call string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	l.calls = append(l.calls, call)
+}
+
+func (l *lifecycleLog) get() []string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	return append([]string(nil), l.calls...)
+}
+
+// orderedService logs its start and stop. If barrier is set, Start waits until every
+// service sharing the barrier has entered Start.
+type orderedService struct {
+	name     string
+	log      *lifecycleLog
+	barrier  *sync.WaitGroup
+	startErr error
+	stopErr  error
+}
+
+func (s *orderedService) Start(ctx context.Context) error {
+	s.log.add("start " + s.name)
+
+	if s.barrier != nil {
+		s.barrier.Done()
+
+		done := make(chan struct{})
+		go func() {
+			s.barrier.Wait()
+			close(done)
+		}()
+
+		select {
+		case <-done:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+
+	if s.startErr != nil {
+		return s.startErr
+	}
+
+	s.log.add("started " + s.name)
+
+	return nil
+}
+
+func (s *orderedService) Stop(context.Context) error {
+	s.log.add("stop " + s.name)
+
+	return s.stopErr
+}
+
+type (
+	QueueService struct{ *orderedService }
+	CacheService struct{ *orderedService }
+	APIService   struct{ *orderedService }
+	APIHandlers  struct{ queue *QueueService }
+)
+
+func TestApp_DependencyOrder(t *testing.T) {
+	log := &lifecycleLog{}
+	barrier := &sync.WaitGroup{}
+	barrier.Add(2)
+
+	c := di.New()
+	c.Provide(func(h *APIHandlers, cache *CacheService) *APIService {
+		return &APIService{&orderedService{name: "api", log: log}}
+	})
+	c.Provide(func(q *QueueService) *APIHandlers { return &APIHandlers{queue: q} })
+	c.Provide(func() *QueueService {
+		return &QueueService{&orderedService{name: "queue", log: log, barrier: barrier}}
+	})
+	c.Provide(func() *CacheService {
+		return &CacheService{&orderedService{name: "cache", log: log, barrier: barrier}}
+	})
+
+	di.MustResolve[*APIService](c)
+
+	app := di.NewApp(c, di.WithStartTimeout(time.Second))
+
+	// queue and cache only finish starting when both have entered Start.
+	require.NoError(t, app.Start(context.Background()))
+
+	calls := log.get()
+	require.ElementsMatch(t, []string{"start queue", "start cache"}, calls[:2])
+	require.Equal(t, "start api", calls[4])
+
+	require.NoError(t, app.Stop(context.Background()))
+
+	calls = log.get()[6:]
+	require.Equal(t, "stop api", calls[0])
+	require.ElementsMatch(t, []string{"stop queue", "stop cache"}, calls[1:])
+}
+
+func TestApp_MaxParallelism(t *testing.T) {
+	log := &lifecycleLog{}
+
+	c := di.New()
+	c.Provide(func() *QueueService { return &QueueService{&orderedService{name: "queue", log: log}} })
+	c.Provide(func() *CacheService { return &CacheService{&orderedService{name: "cache", log: log}} })
+
+	di.MustResolve[*QueueService](c)
+	di.MustResolve[*CacheService](c)
+
+	app := di.NewApp(c, di.WithMaxParallelism(1))
+	require.NoError(t, app.Start(context.Background()))
+	require.Equal(t, []string{"start queue", "started queue", "start cache", "started cache"}, log.get())
+}
+
+func TestApp_StartRollback(t *testing.T) {
+	errStart := errors.New("api failed")
+	errStop := errors.New("queue failed")
+	log := &lifecycleLog{}
+
+	c := di.New()
+	c.Provide(func(q *QueueService, cache *CacheService) *APIService {
+		return &APIService{&orderedService{name: "api", log: log, startErr: errStart}}
+	})
+	c.Provide(func() *QueueService {
+		return &QueueService{&orderedService{name: "queue", log: log, stopErr: errStop}}
+	})
+	c.Provide(func() *CacheService { return &CacheService{&orderedService{name: "cache", log: log}} })
+
+	di.MustResolve[*APIService](c)
+
+	app := di.NewApp(c, di.WithMaxParallelism(1))
+	err := app.Run(context.Background())
+	require.ErrorIs(t, err, errStart)
+	require.ErrorIs(t, err, errStop)
+	require.Equal(t, []string{
+		"start queue", "started queue",
+		"start cache", "started cache",
+		"start api",
+		"stop cache", "stop queue",
+	}, log.get())
+}
+
+// stubbornService ignores the context in Start and only returns once released.
+type stubbornService struct {
+	log     *lifecycleLog
+	entered chan struct{}
+	release chan struct{}
+}
+
+func (s *stubbornService) Start(context.Context) error {
+	close(s.entered)
+	<-s.release
+	s.log.add("started stubborn")
+
+	return nil
+}
+
+func (s *stubbornService) Stop(context.Context) error {
+	s.log.add("stop stubborn")
+
+	return nil
+}
+
+// gatedFailure fails to start once the stubborn service has entered Start.
+type gatedFailure struct {
+	stubborn *stubbornService
+	err      error
+}
+
+func (s *gatedFailure) Start(context.Context) error {
+	<-s.stubborn.entered
+
+	return s.err
+}
+
+func (s *gatedFailure) Stop(context.Context) error { return nil }
+
+func TestApp_StartRollbackLateStart(t *testing.T) {
+	errStart := errors.New("api failed")
+	log := &lifecycleLog{}
+	stubborn := &stubbornService{log: log, entered: make(chan struct{}), release: make(chan struct{})}
+
+	c := di.New()
+	c.Provide(func() *stubbornService { return stubborn })
+	c.Provide(func() *gatedFailure { return &gatedFailure{stubborn: stubborn, err: errStart} })
+
+	di.MustResolve[*stubbornService](c)
+	di.MustResolve[*gatedFailure](c)
+
+	app := di.NewApp(c)
+	require.ErrorIs(t, app.Start(context.Background()), errStart)
+	require.Empty(t, log.get())
+
+	close(stubborn.release)
+	require.Eventually(t, func() bool {
+		return len(log.get()) == 2
+	}, time.Second, 5*time.Millisecond)
+	require.Equal(t, []string{"started stubborn", "stop stubborn"}, log.get())
+}
+
+type slowService struct{}
+
+func (slowService) Start(ctx context.Context) error {
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+func (slowService) Stop(context.Context) error {
+	return errors.New("already closed")
+}
+
+func NewSlowService() *slowService {
+	return &slowService{}
+}
+
+func TestApp_ServiceIdentity(t *testing.T) {
+	var buf bytes.Buffer
+	logger := slog.New(slog.NewJSONHandler(&buf, nil))
+
+	c := di.New()
+	c.Provide(NewSlowService).StartTimeout(50 * time.Millisecond)
+	di.MustResolve[*slowService](c)
+
+	app := di.NewApp(c, di.WithLogger(logger), di.WithStartTimeout(time.Second))
+
+	started := time.Now()
+	err := app.Start(context.Background())
+	require.Less(t, time.Since(started), time.Second)
+	require.ErrorIs(t, err, context.DeadlineExceeded)
+
+	var svcErr *di.ServiceError
+	require.ErrorAs(t, err, &svcErr)
+	require.Equal(t, "start", svcErr.Op)
+	require.Equal(t, "github.com/rom8726/di_test.NewSlowService", svcErr.Service.Constructor)
+	require.Equal(t, "start di_test.NewSlowService (*di_test.slowService): context deadline exceeded", err.Error())
+
+	err = app.Stop(context.Background())
+	require.ErrorContains(t, err, "stop di_test.NewSlowService (*di_test.slowService): already closed")
+
+	out := buf.String()
+	require.Contains(t, out, `"msg":"Service failed to start","service":"di_test.NewSlowService","type":"*di_test.slowService","duration":`)
+	require.Contains(t, out, `"msg":"Service failed to stop"`)
+}
+
+type closerConn struct{ log *lifecycleLog }
+
+func (c *closerConn) Close() error {
+	c.log.add("close conn")
+
+	return nil
+}
+
+type shutdownServer struct {
+	log  *lifecycleLog
+	conn *closerConn
+}
+
+func (s *shutdownServer) Shutdown(context.Context) error {
+	s.log.add("shutdown server")
+
+	return nil
+}
+
+func (s *shutdownServer) Close() error {
+	s.log.add("close server")
+
+	return nil
+}
+
+type hookedWorker struct{ server *shutdownServer }
+
+func TestApp_Hooks(t *testing.T) {
+	log := &lifecycleLog{}
+
+	c := di.New()
+	c.Provide(func() *closerConn { return &closerConn{log: log} })
+	c.Provide(func(conn *closerConn) *shutdownServer { return &shutdownServer{log: log, conn: conn} })
+	c.Provide(func(s *shutdownServer) *hookedWorker { return &hookedWorker{server: s} }).
+		OnStart(func(ctx context.Context, w *hookedWorker) error {
+			log.add("start worker")
+
+			return nil
+		}).
+		OnStop(func(ctx context.Context, w *hookedWorker) error {
+			log.add("stop worker")
+
+			return nil
+		})
+
+	di.MustResolve[*hookedWorker](c)
+
+	app := di.NewApp(c)
+	require.NoError(t, app.Start(context.Background()))
+	require.NoError(t, app.Stop(context.Background()))
+	require.Equal(t, []string{"start worker", "stop worker", "shutdown server", "close conn"}, log.get())
+}
+
+func TestApp_SuppliedNotClosed(t *testing.T) {
+	log := &lifecycleLog{}
+
+	c := di.New()
+	c.Supply(&closerConn{log: log})
+	c.Supply(&shutdownServer{log: log}).
+		OnStop(func(ctx context.Context, s *shutdownServer) error {
+			log.add("stop hook")
+
+			return nil
+		})
+
+	app := di.NewApp(c)
+	require.NoError(t, app.Start(context.Background()))
+	require.NoError(t, app.Stop(context.Background()))
+	require.Equal(t, []string{"stop hook"}, log.get())
+}
+
+func TestProvider_OnStartPanics(t *testing.T) {
+	c := di.New()
+	p := c.Provide(func() *hookedWorker { return &hookedWorker{} })
+
+	require.Panics(t, func() { p.OnStart(func(*hookedWorker) error { return nil }) })
+	require.Panics(t, func() { p.OnStop(func(context.Context, *closerConn) error { return nil }) })
+	require.Panics(t, func() { p.OnStop(nil) })
+}
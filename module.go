@@ -0,0 +1,91 @@
+package di
+
+import (
+	"fmt"
+)
+
+// Module groups providers into a reusable unit that can be installed into a container.
+// Modules may include other modules. Providers marked with Provider.Private are only
+// visible to other providers of the same module.
+type Module struct {
+	name      string
+	providers []*Provider
+	modules   []*Module
+}
+
+// NewModule creates an empty module. The name is shown in errors and graph output
+// and must be unique within a container.
+func NewModule(name string) *Module {
+	return &Module{name: name}
+}
+
+func (m *Module) Name() string {
+	return m.name
+}
+
+// Provide adds a constructor to the module.
+func (m *Module) Provide(constructor any) *Provider {
+	prvdr := newProvider(constructor)
+	prvdr.module = m
+	for _, out := range prvdr.outs {
+		out.module = m
+	}
+
+	m.providers = append(m.providers, prvdr)
+
+	return prvdr
+}
+
+// Include nests other modules. They are installed together with m.
+func (m *Module) Include(modules ...*Module) *Module {
+	m.modules = append(m.modules, modules...)
+
+	return m
+}
+
+// Install registers the providers of the modules and of all nested modules.
+// It panics if a module, or another module with the same name, is installed twice, or if
+// two providers share a type, name and private module.
+func (c *Container) Install(modules ...*Module) {
+	for _, m := range modules {
+		c.install(m)
+	}
+}
+
+func (c *Container) install(m *Module) {
+	c.mu.Lock()
+	if c.modules == nil {
+		c.modules = make(map[string]*Module)
+	}
+
+	if _, ok := c.modules[m.name]; ok {
+		c.mu.Unlock()
+		panic(fmt.Errorf("duplicate module %q", m.name))
+	}
+
+	c.modules[m.name] = m
+	c.mu.Unlock()
+
+	for _, prvdr := range m.providers {
+		c.register(prvdr)
+	}
+
+	for _, sub := range m.modules {
+		c.install(sub)
+	}
+}
+
+// visibleTo filters out private providers of other modules. A nil requester stands
+// for a caller outside the container and sees no private providers.
+func visibleTo(providers []*Provider, requester *Provider) []*Provider {
+	visible := make([]*Provider, 0, len(providers))
+	for _, prov := range providers {
+		if prov.private && (requester == nil || requester.module != prov.module) {
+			continue
+		}
+
+		visible = append(visible, prov)
+	}
+
+	return visible
+}
@@ -0,0 +1,98 @@
+package di_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+type DBConfig struct {
+	DSN string
+}
+
+func NewDBClientFromConfig(cfg *DBConfig) *DBClientImpl {
+	return NewDBClient(cfg.DSN)
+}
+
+func newDBModule() *di.Module {
+	m := di.NewModule("db")
+	m.Provide(func() *DBConfig { return &DBConfig{DSN: "dsn"} }).Private()
+	m.Provide(NewDBClientFromConfig)
+
+	return m
+}
+
+func TestContainer_Install(t *testing.T) {
+	repoModule := di.NewModule("repo").Include(newDBModule())
+	repoModule.Provide(NewRepo)
+
+	c := di.New()
+	c.Install(repoModule)
+
+	repo, err := di.Resolve[Repo](c)
+	require.NoError(t, err)
+
+	data, err := repo.Find()
+	require.NoError(t, err)
+	require.Equal(t, "dsn", data)
+}
+
+func TestContainer_InstallPrivateProvider(t *testing.T) {
+	c := di.New()
+	c.Install(newDBModule())
+	c.Provide(func(cfg *DBConfig) string { return cfg.DSN })
+
+	_, err := di.Resolve[*DBConfig](c)
+	require.ErrorContains(t, err, "missing dependency *di_test.DBConfig")
+
+	_, err = di.Resolve[string](c)
+	require.Error(t, err)
+
+	_, err = di.Resolve[*DBClientImpl](c)
+	require.NoError(t, err)
+}
+
+func TestContainer_InstallDuplicateModule(t *testing.T) {
+	c := di.New()
+	c.Install(newDBModule())
+
+	require.PanicsWithError(t, `duplicate module "db"`, func() { c.Install(newDBModule()) })
+}
+
+func TestContainer_ModuleInErrorsAndGraph(t *testing.T) {
+	m := di.NewModule("services")
+	m.Provide(NewMyService)
+
+	c := di.New()
+	c.Install(m)
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(NewRepo)
+
+	_, err := di.Resolve[*MyService](c)
+	require.ErrorContains(t, err, "[services] di_test.NewMyService (missing *di_test.MyServiceParams)")
+
+	var sb strings.Builder
+	require.NoError(t, c.Graph().WriteDOT(&sb))
+	require.Contains(t, sb.String(), "subgraph cluster_1 {\n    label=\"services\";\n    p0 [")
+}
+
+func TestContainer_InstallDuplicateProvider(t *testing.T) {
+	cache := di.NewModule("cache")
+	cache.Provide(func() *DBConfig { return &DBConfig{DSN: "cache"} }).Private()
+
+	c := di.New()
+	require.NotPanics(t, func() { c.Install(newDBModule(), cache) })
+
+	m := di.NewModule("twice")
+	m.Provide(func() *DBConfig { return &DBConfig{} }).Private()
+	m.Provide(func() *DBConfig { return &DBConfig{} }).Private()
+
+	require.PanicsWithError(t,
+		`duplicate provider *di_test.DBConfig in module "twice": `+
+			"github.com/rom8726/di_test.TestContainer_InstallDuplicateProvider.func3, "+
+			"github.com/rom8726/di_test.TestContainer_InstallDuplicateProvider.func4",
+		func() { di.New().Install(m) })
+}
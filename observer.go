@@ -0,0 +1,292 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"os"
+	"slices"
+	"time"
+)
+
+// Observer receives the events of a container and an App. Events are delivered
+// synchronously and possibly concurrently, so Observe must be fast, safe for concurrent
+// use and must not use the container.
+type Observer interface {
+	Observe(event Event)
+}
+
+// ObserverFunc adapts a function to Observer.
+type ObserverFunc func(event Event)
+
+func (f ObserverFunc) Observe(event Event) {
+	f(event)
+}
+
+// Event is one of the event types below.
+type Event interface {
+	event()
+}
+
+// ProviderBuildStarted is emitted before a constructor is called.
+type ProviderBuildStarted struct {
+	Provider Frame
+}
+
+// ProviderBuildFinished is emitted after a constructor and its decorators returned.
+type ProviderBuildFinished struct {
+	Provider Frame
+	Duration time.Duration
+	Err      error
+}
+
+// AppStarting is emitted when App.Start begins.
+type AppStarting struct{}
+
+// AppStarted is emitted when App.Start has started all services or failed.
+type AppStarted struct {
+	Duration time.Duration
+	Err      error
+}
+
+// ShutdownReason tells why an App is stopping.
+type ShutdownReason string
+
+const (
+	ShutdownStopCalled   ShutdownReason = "stop called"
+	ShutdownContextDone  ShutdownReason = "context done"
+	ShutdownSignal       ShutdownReason = "signal"
+	ShutdownRunnerFailed ShutdownReason = "runner failed"
+	ShutdownStartFailed  ShutdownReason = "start failed"
+)
+
+// AppStopping is emitted when the App begins to stop. Signal is set for ShutdownSignal.
+type AppStopping struct {
+	Reason ShutdownReason
+	Signal os.Signal
+}
+
+// AppStopped is emitted when the App has stopped.
+type AppStopped struct {
+	Duration time.Duration
+	Err      error
+}
+
+// ServiceStarting is emitted before a service is started.
+type ServiceStarting struct {
+	Service Frame
+}
+
+// ServiceStarted is emitted after a service started or failed to start.
+type ServiceStarted struct {
+	Service  Frame
+	Duration time.Duration
+	Err      error
+}
+
+// ServiceStopping is emitted before a service is stopped.
+type ServiceStopping struct {
+	Service Frame
+}
+
+// ServiceStopped is emitted after a service stopped or failed to stop.
+type ServiceStopped struct {
+	Service  Frame
+	Duration time.Duration
+	Err      error
+}
+
+// Timeout is emitted when an operation runs out of time. Op is "start", "stop" or "run".
+// Service is zero for timeouts that do not concern a single service.
+type Timeout struct {
+	Op      string
+	Service Frame
+}
+
+// RunnerFailed is emitted when a Runner returns an error or panics. Restart is the number
+// of the upcoming restart, or zero if the failure is final.
+type RunnerFailed struct {
+	Service Frame
+	Err     error
+	Restart int
+	Backoff time.Duration
+}
+
+// SignalReceived is emitted by RunWithSignals. Action is "shutdown", "drain", "exit" or "reload".
+type SignalReceived struct {
+	Signal os.Signal
+	Action string
+}
+
+// ServiceReloaded is emitted after a Reloader reloaded or failed to.
+type ServiceReloaded struct {
+	Service Frame
+	Err     error
+}
+
+func (ProviderBuildStarted) event()  {}
+func (ProviderBuildFinished) event() {}
+func (AppStarting) event()           {}
+func (AppStarted) event()            {}
+func (AppStopping) event()           {}
+func (AppStopped) event()            {}
+func (ServiceStarting) event()       {}
+func (ServiceStarted) event()        {}
+func (ServiceStopping) event()       {}
+func (ServiceStopped) event()        {}
+func (Timeout) event()               {}
+func (RunnerFailed) event()          {}
+func (SignalReceived) event()        {}
+func (ServiceReloaded) event()       {}
+
+// WithObserver adds an observer of the App and of its container, like Container.AddObserver.
+// Construction events are only reported for instances built after NewApp, so add observers
+// with Container.AddObserver before resolving to see every construction.
+func WithObserver(observer Observer) AppOpt {
+	return func(app *App) {
+		app.observers = append(app.observers, observer)
+	}
+}
+
+// AddObserver adds an observer of constructor calls and of the events of every App created
+// for the container. Add observers before resolving, since constructions that have already
+// happened are not reported.
+func (c *Container) AddObserver(observer Observer) {
+	root := c.root()
+	root.observersMu.Lock()
+	defer root.observersMu.Unlock()
+
+	// Never append in place, emit iterates over the previous slice without the lock.
+	root.observers = append(slices.Clip(root.observers), observer)
+}
+
+func (c *Container) emit(event Event) {
+	root := c.root()
+	root.observersMu.Lock()
+	observers := root.observers
+	root.observersMu.Unlock()
+
+	for _, observer := range observers {
+		observer.Observe(event)
+	}
+}
+
+func (app *App) emit(event Event) {
+	app.container.emit(event)
+}
+
+// NewSlogObserver returns an observer that logs events to logger. Constructor calls are
+// logged at debug level. A nil logger logs nothing.
+func NewSlogObserver(logger *slog.Logger) Observer {
+	if logger == nil {
+		return ObserverFunc(func(Event) {})
+	}
+
+	return &slogObserver{logger: logger}
+}
+
+type slogObserver struct {
+	logger *slog.Logger
+}
+
+func (o *slogObserver) Observe(event Event) {
+	switch e := event.(type) {
+	case ProviderBuildStarted:
+		o.logger.Debug("Building provider", serviceAttrs(e.Provider)...)
+	case ProviderBuildFinished:
+		attrs := append(serviceAttrs(e.Provider), slog.Duration("duration", e.Duration))
+		if e.Err != nil {
+			o.logger.Debug("Provider failed", append(attrs, slog.Any("error", e.Err))...)
+
+			return
+		}
+
+		o.logger.Debug("Provider built", attrs...)
+	case AppStarting:
+		o.logger.Info("Starting...")
+	case AppStarted:
+		switch {
+		case errors.Is(e.Err, context.DeadlineExceeded):
+			o.logger.Error("Start timed out.", slog.Any("error", e.Err))
+		case e.Err != nil:
+			o.logger.Error("Failed to start.", slog.Any("error", e.Err))
+		default:
+			o.logger.Info("Started.", slog.Duration("duration", e.Duration))
+		}
+	case AppStopping:
+		attrs := []any{slog.String("reason", string(e.Reason))}
+		if e.Signal != nil {
+			attrs = append(attrs, slog.String("signal", e.Signal.String()))
+		}
+
+		o.logger.Info("Stopping...", attrs...)
+	case AppStopped:
+		switch {
+		case errors.Is(e.Err, context.DeadlineExceeded):
+			o.logger.Error("Stop timed out.", slog.Any("error", e.Err))
+		case e.Err != nil:
+			o.logger.Error("Failed to stop cleanly.", slog.Any("error", e.Err))
+		default:
+			o.logger.Info("Stopped.", slog.Duration("duration", e.Duration))
+		}
+	case ServiceStarting:
+		o.logger.Debug("Starting service", serviceAttrs(e.Service)...)
+	case ServiceStarted:
+		o.logResult("Service started", "Service failed to start", e.Service, e.Duration, e.Err)
+	case ServiceStopping:
+		o.logger.Debug("Stopping service", serviceAttrs(e.Service)...)
+	case ServiceStopped:
+		o.logResult("Service stopped", "Service failed to stop", e.Service, e.Duration, e.Err)
+	case Timeout:
+		o.logger.Warn("Timed out", append([]any{slog.String("op", e.Op)}, serviceAttrs(e.Service)...)...)
+	case RunnerFailed:
+		attrs := append(serviceAttrs(e.Service), slog.Any("error", e.Err))
+		if e.Restart == 0 {
+			o.logger.Error("Runner failed", attrs...)
+
+			return
+		}
+
+		o.logger.Warn("Runner failed, restarting",
+			append(attrs, slog.Int("restart", e.Restart), slog.Duration("backoff", e.Backoff))...)
+	case SignalReceived:
+		level := slog.LevelInfo
+		if e.Action == "exit" {
+			level = slog.LevelError
+		}
+
+		o.logger.Log(context.Background(), level, "Received signal",
+			slog.String("signal", e.Signal.String()), slog.String("action", e.Action))
+	case ServiceReloaded:
+		if e.Err != nil {
+			o.logger.Error("Service failed to reload", append(serviceAttrs(e.Service), slog.Any("error", e.Err))...)
+
+			return
+		}
+
+		o.logger.Info("Service reloaded", serviceAttrs(e.Service)...)
+	}
+}
+
+func (o *slogObserver) logResult(msg, failMsg string, service Frame, d time.Duration, err error) {
+	attrs := append(serviceAttrs(service), slog.Duration("duration", d))
+	if err != nil {
+		o.logger.Error(failMsg, append(attrs, slog.Any("error", err))...)
+
+		return
+	}
+
+	o.logger.Info(msg, attrs...)
+}
+
+// serviceAttrs describes a provider as log attributes. A zero frame has none.
+func serviceAttrs(frame Frame) []any {
+	if frame.Type == nil {
+		return nil
+	}
+
+	return []any{
+		slog.String("service", frame.String()),
+		slog.String("type", frame.Type.String()),
+	}
+}
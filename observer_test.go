@@ -0,0 +1,171 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+type eventRecorder struct {
+	mu     sync.Mutex
+	events []di.Event
+}
+
+func (r *eventRecorder) Observe(event di.Event) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.events = append(r.events, event)
+}
+
+func (r *eventRecorder) kinds() []string {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	kinds := make([]string, 0, len(r.events))
+	for _, event := range r.events {
+		kinds = append(kinds, fmt.Sprintf("%T", event))
+	}
+
+	return kinds
+}
+
+func (r *eventRecorder) find(kind string) di.Event {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for _, event := range r.events {
+		if fmt.Sprintf("%T", event) == kind {
+			return event
+		}
+	}
+
+	return nil
+}
+
+func TestApp_Observer(t *testing.T) {
+	rec := &eventRecorder{}
+	log := &lifecycleLog{}
+
+	c := di.New()
+	c.Provide(func() *QueueService { return &QueueService{&orderedService{name: "queue", log: log}} })
+
+	app := di.NewApp(c, di.WithObserver(rec))
+	di.MustResolve[*QueueService](c)
+
+	require.NoError(t, app.Start(context.Background()))
+	require.NoError(t, app.Stop(context.Background()))
+
+	require.Equal(t, []string{
+		"di.ProviderBuildStarted",
+		"di.ProviderBuildFinished",
+		"di.AppStarting",
+		"di.ServiceStarting",
+		"di.ServiceStarted",
+		"di.AppStarted",
+		"di.AppStopping",
+		"di.ServiceStopping",
+		"di.ServiceStopped",
+		"di.AppStopped",
+	}, rec.kinds())
+
+	built := rec.find("di.ProviderBuildFinished").(di.ProviderBuildFinished)
+	require.Equal(t, "*di_test.QueueService", built.Provider.Type.String())
+	require.NoError(t, built.Err)
+
+	require.Equal(t, di.ShutdownStopCalled, rec.find("di.AppStopping").(di.AppStopping).Reason)
+}
+
+func TestContainer_AddObserver(t *testing.T) {
+	rec := &eventRecorder{}
+
+	c := di.New()
+	c.AddObserver(rec)
+	c.Provide(func() *QueueService { return &QueueService{&orderedService{name: "queue", log: &lifecycleLog{}}} })
+	di.MustResolve[*QueueService](c)
+
+	app := di.NewApp(c)
+	require.NoError(t, app.Start(context.Background()))
+
+	require.Equal(t, []string{
+		"di.ProviderBuildStarted",
+		"di.ProviderBuildFinished",
+		"di.AppStarting",
+		"di.ServiceStarting",
+		"di.ServiceStarted",
+		"di.AppStarted",
+	}, rec.kinds())
+}
+
+func TestContainer_AddObserverWhileResolving(t *testing.T) {
+	c := di.New()
+	c.AddObserver(&eventRecorder{})
+	c.Provide(func() *RequestCtx {
+		time.Sleep(20 * time.Millisecond)
+
+		return &RequestCtx{}
+	}).Scoped()
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := di.Resolve[*RequestCtx](c.NewScope())
+		done <- err
+	}()
+
+	time.Sleep(10 * time.Millisecond)
+	c.AddObserver(&eventRecorder{})
+	require.NoError(t, <-done)
+}
+
+func TestApp_NilLogger(t *testing.T) {
+	log := &lifecycleLog{}
+	c := di.New()
+	c.Provide(func() *QueueService { return &QueueService{&orderedService{name: "queue", log: log}} })
+	di.MustResolve[*QueueService](c)
+
+	app := di.NewApp(c, di.WithLogger(nil))
+	require.NoError(t, app.Start(context.Background()))
+	require.NoError(t, app.Stop(context.Background()))
+	require.Equal(t, []string{"start queue", "started queue", "stop queue"}, log.get())
+}
+
+func TestApp_ObserverFailures(t *testing.T) {
+	errCtor := errors.New("ctor failed")
+
+	rec := &eventRecorder{}
+	c := di.New()
+	c.Provide(func() (*QueueService, error) { return nil, errCtor })
+	c.Provide(NewSlowService).StartTimeout(10 * time.Millisecond)
+
+	app := di.NewApp(c, di.WithObserver(rec))
+
+	_, err := di.Resolve[*QueueService](c)
+	require.ErrorIs(t, err, errCtor)
+	require.ErrorIs(t, rec.find("di.ProviderBuildFinished").(di.ProviderBuildFinished).Err, errCtor)
+
+	di.MustResolve[*slowService](c)
+	require.ErrorIs(t, app.Start(context.Background()), context.DeadlineExceeded)
+
+	timeout := rec.find("di.Timeout").(di.Timeout)
+	require.Equal(t, "start", timeout.Op)
+	require.Equal(t, "*di_test.slowService", timeout.Service.Type.String())
+	require.Equal(t, di.ShutdownStartFailed, rec.find("di.AppStopping").(di.AppStopping).Reason)
+}
+
+func TestApp_ObserverShutdownReason(t *testing.T) {
+	rec := &eventRecorder{}
+	c := di.New()
+	c.Provide(func() *flakyWorker { return &flakyWorker{failures: 1, err: errors.New("worker failed")} })
+	di.MustResolve[*flakyWorker](c)
+
+	require.Error(t, di.NewApp(c, di.WithObserver(rec)).Run(context.Background()))
+	require.Equal(t, di.ShutdownRunnerFailed, rec.find("di.AppStopping").(di.AppStopping).Reason)
+	require.Zero(t, rec.find("di.RunnerFailed").(di.RunnerFailed).Restart)
+}
@@ -0,0 +1,116 @@
+package di
+
+import (
+	"errors"
+	"reflect"
+)
+
+// Optional wraps a constructor parameter that may be missing, e.g. func(t di.Optional[*Tracer]).
+// When no provider exists for T the parameter holds the zero value. Errors of an existing
+// provider still fail the resolution.
+type Optional[T any] struct {
+	value T
+	ok    bool
+}
+
+// Get returns the resolved value and whether a provider existed.
+func (o Optional[T]) Get() (T, bool) {
+	return o.value, o.ok
+}
+
+// Value returns the resolved value or the zero value of T.
+func (o Optional[T]) Value() T {
+	return o.value
+}
+
+func (Optional[T]) optionalType() reflect.Type {
+	return reflect.TypeFor[T]()
+}
+
+func (o *Optional[T]) setOptional(v reflect.Value) {
+	reflect.ValueOf(&o.value).Elem().Set(v)
+	o.ok = true
+}
+
+type optionalParam interface {
+	optionalType() reflect.Type
+}
+
+type optionalSetter interface {
+	setOptional(v reflect.Value)
+}
+
+var optionalParamType = reflect.TypeFor[optionalParam]()
+
+// optionalElem returns T if t is Optional[T].
+func optionalElem(t reflect.Type) (reflect.Type, bool) {
+	if t.Kind() != reflect.Struct || !t.Implements(optionalParamType) {
+		return nil, false
+	}
+
+	return reflect.Zero(t).Interface().(optionalParam).optionalType(), true
+}
+
+// resolveParam resolves a constructor parameter or a struct field. Optional[T] parameters
+// and optional fields resolve to the zero value when no provider exists. Lazy[T] and
+// func() (T, error) parameters defer the resolution of T.
+func (c *Container) resolveParam(t reflect.Type, name string, optional bool) (reflect.Value, error) {
+	if elem, ok := optionalElem(t); ok {
+		inst, found, err := c.getOptionalInstance(elem, name)
+		if err != nil {
+			return reflect.Value{}, err
+		}
+
+		opt := reflect.New(t)
+		if found {
+			opt.Interface().(optionalSetter).setOptional(inst)
+		}
+
+		return opt.Elem(), nil
+	}
+
+	if embeds(t, inType) {
+		return c.buildIn(t)
+	}
+
+	if elem, ok := lazyElem(t); ok {
+		return c.newLazy(t, elem, name), nil
+	}
+
+	if elem, ok := factoryElem(t); ok && !c.hasProvider(t, name) {
+		return c.newFactory(t, elem, name), nil
+	}
+
+	if optional {
+		inst, found, err := c.getOptionalInstance(t, name)
+		if err != nil || !found {
+			return reflect.Zero(t), err
+		}
+
+		return inst, nil
+	}
+
+	return c.getInstanceByType(t, name)
+}
+
+func (c *Container) hasProvider(t reflect.Type, name string) bool {
+	found, err := findProvider(c.visibleProviders(), t, name)
+
+	return found != nil || err != nil
+}
+
+func (c *Container) getOptionalInstance(t reflect.Type, name string) (reflect.Value, bool, error) {
+	inst, err := c.getInstanceByType(t, name)
+	if err != nil {
+		// Only a missing provider for t itself makes the dependency absent,
+		// a missing dependency further down the chain is still an error.
+		var missingErr *MissingDependencyError
+		if errors.As(err, &missingErr) && missingErr.Type == t && len(missingErr.Chain) == len(c.resolving) {
+			return reflect.Value{}, false, nil
+		}
+
+		return reflect.Value{}, false, err
+	}
+
+	return inst, true, nil
+}
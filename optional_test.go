@@ -0,0 +1,90 @@
+package di_test
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+type Tracer struct {
+	name string
+}
+
+type TracedService struct {
+	tracer *Tracer
+	found  bool
+}
+
+func NewTracedService(tracer di.Optional[*Tracer]) *TracedService {
+	t, ok := tracer.Get()
+
+	return &TracedService{tracer: t, found: ok}
+}
+
+func TestOptional_Missing(t *testing.T) {
+	c := di.New()
+	c.Provide(NewTracedService)
+
+	require.NoError(t, c.Validate())
+
+	svc, err := di.Resolve[*TracedService](c)
+	require.NoError(t, err)
+	require.Nil(t, svc.tracer)
+	require.False(t, svc.found)
+}
+
+func TestOptional_Present(t *testing.T) {
+	c := di.New()
+	c.Provide(func() *Tracer { return &Tracer{name: "jaeger"} })
+	c.Provide(NewTracedService)
+
+	svc, err := di.Resolve[*TracedService](c)
+	require.NoError(t, err)
+	require.True(t, svc.found)
+	require.Equal(t, "jaeger", svc.tracer.name)
+}
+
+func TestOptional_ProviderErrorPropagates(t *testing.T) {
+	errTracer := errors.New("tracer error")
+
+	c := di.New()
+	c.Provide(func() (*Tracer, error) { return nil, errTracer })
+	c.Provide(NewTracedService)
+
+	_, err := di.Resolve[*TracedService](c)
+	require.ErrorIs(t, err, errTracer)
+}
+
+func TestOptional_MissingTransitiveDependency(t *testing.T) {
+	c := di.New()
+	c.Provide(func(cfg *DBConfig) *Tracer { return &Tracer{name: cfg.DSN} })
+	c.Provide(NewTracedService)
+
+	_, err := di.Resolve[*TracedService](c)
+
+	var missingErr *di.MissingDependencyError
+	require.ErrorAs(t, err, &missingErr)
+	require.Equal(t, "*di_test.DBConfig", missingErr.Type.String())
+}
+
+func TestOptional_StructTag(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+
+	type Holder struct {
+		DB     *DBClientImpl
+		Tracer *Tracer `di:"optional"`
+		Repo   di.Optional[Repo]
+	}
+
+	var holder Holder
+	require.NoError(t, c.ResolveToStruct(&holder))
+	require.NotNil(t, holder.DB)
+	require.Nil(t, holder.Tracer)
+
+	_, ok := holder.Repo.Get()
+	require.False(t, ok)
+}
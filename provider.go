@@ -1,19 +1,201 @@
 package di
 
 import (
+	"context"
+	"fmt"
 	"reflect"
 	"runtime"
+	"slices"
+	"time"
 )
 
+// Lifetime controls how long an instance built by a provider is reused.
+type Lifetime int
+
+const (
+	// Singleton instances are built once and cached in the root container.
+	Singleton Lifetime = iota
+	// Transient instances are built on every resolution and never cached.
+	Transient
+	// Scoped instances are built once per scope created with Container.NewScope.
+	Scoped
+)
+
+func (l Lifetime) String() string {
+	switch l {
+	case Singleton:
+		return "singleton"
+	case Transient:
+		return "transient"
+	case Scoped:
+		return "scoped"
+	default:
+		return "unknown"
+	}
+}
+
 type Provider struct {
 	name       string
+	file       string
+	line       int
 	returnType reflect.Type
 	paramTypes []reflect.Type
-	initFunc   func(args []any) (any, error)
+	initFunc   func(args []reflect.Value) (any, error)
+	lifetime   Lifetime
+	named      string
+	group      string
+	as         []reflect.Type
+	primary    bool
+	module     *Module
+	private    bool
+	decorator  bool
+	outs       []*Provider
+	bindings   map[int]binding
+	supplied   bool
+	container  *Container
+
+	startTimeout time.Duration
+	stopTimeout  time.Duration
+	onStart      []func(context.Context, any) error
+	onStop       []func(context.Context, any) error
+	restart      RestartPolicy
 
 	args map[reflect.Type]reflect.Value
 }
 
+// Named registers the provider under the given name, so several providers of the same
+// type can coexist. Named providers are only injected where the name is requested.
+// It panics if the container already has a provider of that type and name.
+func (p *Provider) Named(name string) *Provider {
+	p.named = name
+	if p.container != nil {
+		p.container.recheck(p)
+	}
+
+	return p
+}
+
+// Group adds the provider to a value group. All members of a group can be
+// resolved at once as a slice with ResolveGroup.
+func (p *Provider) Group(group string) *Provider {
+	p.group = group
+
+	return p
+}
+
+// As declares that the provider should be used where the interface pointed to by iface
+// is requested, e.g. As(new(Repo)). It disambiguates between several implementations.
+func (p *Provider) As(iface any) *Provider {
+	typ := reflect.TypeOf(iface)
+	if typ == nil || typ.Kind() != reflect.Ptr || typ.Elem().Kind() != reflect.Interface {
+		panic("As expects a pointer to an interface, e.g. new(Iface)")
+	}
+
+	typ = typ.Elem()
+	if !p.returnType.Implements(typ) {
+		panic(fmt.Errorf("%v does not implement %v", p.returnType, typ))
+	}
+
+	p.as = append(p.as, typ)
+
+	return p
+}
+
+// Primary marks the provider as the preferred one when several providers match a requested interface.
+func (p *Provider) Primary() *Provider {
+	p.primary = true
+
+	return p
+}
+
+// Private hides the provider from everything except providers of the same module.
+func (p *Provider) Private() *Provider {
+	p.private = true
+	if p.container != nil {
+		p.container.recheck(p)
+	}
+
+	for _, out := range p.outs {
+		out.Private()
+	}
+
+	return p
+}
+
+// Transient makes the provider build a new instance on every resolution.
+func (p *Provider) Transient() *Provider {
+	p.lifetime = Transient
+	for _, out := range p.outs {
+		out.Transient()
+	}
+
+	return p
+}
+
+// Scoped makes the provider build one instance per scope.
+// Scoped providers can only be resolved from a container created with NewScope.
+func (p *Provider) Scoped() *Provider {
+	p.lifetime = Scoped
+	for _, out := range p.outs {
+		out.Scoped()
+	}
+
+	return p
+}
+
+// StartTimeout limits how long App waits for the Start of this provider's instance.
+// The application start timeout still bounds the whole startup.
+func (p *Provider) StartTimeout(d time.Duration) *Provider {
+	p.startTimeout = d
+	for _, out := range p.outs {
+		out.StartTimeout(d)
+	}
+
+	return p
+}
+
+// StopTimeout limits how long App waits for the Stop of this provider's instance.
+// The application stop timeout still bounds the whole shutdown.
+func (p *Provider) StopTimeout(d time.Duration) *Provider {
+	p.stopTimeout = d
+	for _, out := range p.outs {
+		out.StopTimeout(d)
+	}
+
+	return p
+}
+
+// OnStart registers a hook App calls with the provider's instance when starting, e.g.
+// func(ctx context.Context, db *sql.DB) error. Hooks run in dependency order like Servicer.Start.
+func (p *Provider) OnStart(hook any) *Provider {
+	p.onStart = append(p.onStart, p.newHook(hook))
+
+	return p
+}
+
+// OnStop registers a hook App calls with the provider's instance when stopping.
+// A provider with stop hooks is not stopped through io.Closer or Shutdowner.
+func (p *Provider) OnStop(hook any) *Provider {
+	p.onStop = append(p.onStop, p.newHook(hook))
+
+	return p
+}
+
+func (p *Provider) newHook(hook any) func(context.Context, any) error {
+	fn := reflect.ValueOf(hook)
+	if fn.Kind() != reflect.Func || fn.Type().NumIn() != 2 || fn.Type().In(0) != contextType ||
+		!p.returnType.AssignableTo(fn.Type().In(1)) || fn.Type().NumOut() != 1 || fn.Type().Out(0) != errorType {
+		panic(fmt.Sprintf("hook must be a func(context.Context, %v) error", p.returnType))
+	}
+
+	return func(ctx context.Context, inst any) error {
+		out := fn.Call([]reflect.Value{reflect.ValueOf(ctx), reflect.ValueOf(inst)})
+		err, _ := out[0].Interface().(error)
+
+		return err
+	}
+}
+
 func (p *Provider) Arg(arg any) *Provider {
 	typ := reflect.TypeOf(arg)
 	if _, ok := p.args[typ]; ok {
@@ -38,6 +220,77 @@ func (p *Provider) Args(args ...any) *Provider {
 	return p
 }
 
+// binding configures a single constructor parameter by position.
+// Exactly one of value, name and fn is set.
+type binding struct {
+	value    reflect.Value
+	name     string
+	fn       *Provider
+	provider *Provider
+}
+
+// ArgAt binds the constructor parameter at index to value. Unlike Arg it works for
+// constructors taking several parameters of the same type.
+func (p *Provider) ArgAt(index int, value any) *Provider {
+	pt := p.paramAt(index)
+
+	val := reflect.ValueOf(value)
+	if !val.IsValid() {
+		switch pt.Kind() {
+		case reflect.Interface, reflect.Ptr, reflect.Map, reflect.Slice, reflect.Func, reflect.Chan:
+			val = reflect.Zero(pt)
+		default:
+			panic(fmt.Errorf("nil arg for parameter %d of %s of type %v", index, p.name, pt))
+		}
+	}
+
+	if !val.Type().AssignableTo(pt) {
+		panic(fmt.Errorf("arg of type %v is not assignable to parameter %d of %s of type %v", val.Type(), index, p.name, pt))
+	}
+
+	p.bindings[index] = binding{value: val}
+
+	return p
+}
+
+// ArgNamed resolves the constructor parameter at index from the provider registered with Named(name).
+func (p *Provider) ArgNamed(index int, name string) *Provider {
+	p.paramAt(index)
+	p.bindings[index] = binding{name: name}
+
+	return p
+}
+
+// ArgFunc binds the constructor parameter at index to the result of fn. The parameters of fn
+// are injected like constructor parameters and fn may return an error as its second result.
+// fn is called every time the provider builds an instance.
+func (p *Provider) ArgFunc(index int, fn any) *Provider {
+	pt := p.paramAt(index)
+
+	fnProv := newProvider(fn)
+	if !fnProv.returnType.AssignableTo(pt) {
+		panic(fmt.Errorf("%s returns %v, not assignable to parameter %d of %s of type %v",
+			fnProv.name, fnProv.returnType, index, p.name, pt))
+	}
+
+	fnProv.module = p.module
+	p.bindings[index] = binding{fn: fnProv}
+
+	return p
+}
+
+func (p *Provider) paramAt(index int) reflect.Type {
+	if index < 0 || index >= len(p.paramTypes) {
+		panic(fmt.Errorf("parameter index %d out of range: %s takes %d parameters", index, p.name, len(p.paramTypes)))
+	}
+
+	if _, ok := p.bindings[index]; ok {
+		panic(fmt.Errorf("duplicate binding for parameter %d of %s", index, p.name))
+	}
+
+	return p.paramTypes[index]
+}
+
 func newProvider(constructor any) *Provider {
 	ctor := reflect.ValueOf(constructor)
 	ctorType := ctor.Type()
@@ -53,13 +306,8 @@ func newProvider(constructor any) *Provider {
 		paramTypes[i] = ctorType.In(i)
 	}
 
-	initFunc := func(args []any) (any, error) {
-		var argv []reflect.Value
-		for _, arg := range args {
-			argv = append(argv, reflect.ValueOf(arg))
-		}
-
-		out := ctor.Call(argv)
+	initFunc := func(args []reflect.Value) (any, error) {
+		out := ctor.Call(args)
 		if len(out) == 1 {
 			return out[0].Interface(), nil
 		}
@@ -73,13 +321,57 @@ func newProvider(constructor any) *Provider {
 		return result, nil
 	}
 
-	return &Provider{
+	for _, pt := range paramTypes {
+		if embeds(pt, inType) {
+			if _, err := markedFields(pt, inType); err != nil {
+				panic(err)
+			}
+		}
+	}
+
+	file, line := getFuncLocation(ctor)
+
+	prvdr := &Provider{
 		name:       getFuncName(ctor),
+		file:       file,
+		line:       line,
 		returnType: retType,
 		paramTypes: paramTypes,
 		initFunc:   initFunc,
 		args:       make(map[reflect.Type]reflect.Value),
+		bindings:   make(map[int]binding),
 	}
+
+	if embeds(retType, outType) {
+		prvdr.outs = newOutProviders(prvdr)
+	}
+
+	return prvdr
+}
+
+func (p *Provider) declares(t reflect.Type) bool {
+	return slices.Contains(p.as, t)
+}
+
+func (p *Provider) frame() Frame {
+	return Frame{Type: p.returnType, Constructor: p.name, Module: p.moduleName(), File: p.file, Line: p.line}
+}
+
+func (p *Provider) moduleName() string {
+	if p.module == nil {
+		return ""
+	}
+
+	return p.module.name
+}
+
+func getFuncLocation(fval reflect.Value) (string, int) {
+	fn := runtime.FuncForPC(fval.Pointer())
+	if fn == nil {
+		return "", 0
+	}
+
+	return fn.FileLine(fn.Entry())
 }
 
 func getFuncName(fval reflect.Value) string {
@@ -0,0 +1,177 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// Runner is implemented by components with a blocking loop, like workers and consumers.
+// App.Run calls Run in its own goroutine after startup and cancels ctx on shutdown.
+// Returning before ctx is done, with or without an error, or panicking makes the application
+// shut down, unless the provider has a RestartPolicy that restarts the runner.
+type Runner interface {
+	Run(ctx context.Context) error
+}
+
+// ErrRunnerReturned is the failure of a Runner whose Run returned nil before shutdown.
+var ErrRunnerReturned = errors.New("runner returned before shutdown")
+
+// minRestartBackoff keeps a runner that fails immediately from restarting in a tight loop.
+const minRestartBackoff = 10 * time.Millisecond
+
+// RestartPolicy controls whether a failed Runner is restarted. The zero value, RestartNever,
+// never restarts it.
+type RestartPolicy struct {
+	// MaxRestarts is the number of restarts before the failure is final. Negative means no limit.
+	MaxRestarts int
+	// Backoff is the delay before the first restart. It doubles on every further restart.
+	// Delays below 10ms are raised to 10ms.
+	Backoff time.Duration
+	// MaxBackoff caps the delay. Zero means no cap.
+	MaxBackoff time.Duration
+}
+
+// RestartNever never restarts a failed runner.
+var RestartNever = RestartPolicy{}
+
+// RestartOnFailure restarts a failed runner up to maxRestarts times, negative for no limit,
+// waiting backoff before the first restart and doubling the delay up to maxBackoff.
+func RestartOnFailure(maxRestarts int, backoff, maxBackoff time.Duration) RestartPolicy {
+	return RestartPolicy{MaxRestarts: maxRestarts, Backoff: backoff, MaxBackoff: maxBackoff}
+}
+
+// Restart sets the restart policy used when the provider's instance is a Runner.
+func (p *Provider) Restart(policy RestartPolicy) *Provider {
+	p.restart = policy
+
+	return p
+}
+
+type runner struct {
+	provider *Provider
+	runner   Runner
+}
+
+func (c *Container) runners() []*runner {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	var runners []*runner
+	for _, entry := range c.instancesList {
+		if r, ok := entry.value.(Runner); ok {
+			runners = append(runners, &runner{provider: entry.provider, runner: r})
+		}
+	}
+
+	return runners
+}
+
+// runRunners runs the runners until ctx is done or one of them fails for good, then cancels
+// the others and waits for them to return, at most for the stop timeout.
+func (app *App) runRunners(ctx context.Context) error {
+	runners := app.container.runners()
+
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		firstErr error
+	)
+
+	for _, r := range runners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			if err := app.supervise(ctx, r); err != nil {
+				mu.Lock()
+				if firstErr == nil {
+					firstErr = err
+				}
+				mu.Unlock()
+
+				cancel()
+			}
+		}()
+	}
+
+	<-ctx.Done()
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	var timeout <-chan time.Time
+	if app.stopTimeout > 0 {
+		timer := time.NewTimer(app.stopTimeout)
+		defer timer.Stop()
+
+		timeout = timer.C
+	}
+
+	select {
+	case <-done:
+	case <-timeout:
+		app.emit(Timeout{Op: "run"})
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+
+	return firstErr
+}
+
+// supervise runs r and restarts it according to its provider's policy. Results after ctx
+// is done are ignored.
+func (app *App) supervise(ctx context.Context, r *runner) error {
+	frame := r.provider.frame()
+	policy := r.provider.restart
+	backoff := max(policy.Backoff, minRestartBackoff)
+
+	for restarts := 0; ; restarts++ {
+		err := runSafely(ctx, r.runner)
+		if ctx.Err() != nil {
+			return nil
+		}
+
+		if err == nil {
+			err = ErrRunnerReturned
+		}
+
+		if policy.MaxRestarts >= 0 && restarts >= policy.MaxRestarts {
+			app.emit(RunnerFailed{Service: frame, Err: err})
+
+			return &ServiceError{Op: "run", Service: frame, Err: err}
+		}
+
+		app.emit(RunnerFailed{Service: frame, Err: err, Restart: restarts + 1, Backoff: backoff})
+
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-time.After(backoff):
+		}
+
+		backoff *= 2
+		if policy.MaxBackoff > 0 {
+			backoff = max(min(backoff, policy.MaxBackoff), minRestartBackoff)
+		}
+	}
+}
+
+func runSafely(ctx context.Context, r Runner) (err error) {
+	defer func() {
+		if rec := recover(); rec != nil {
+			err = fmt.Errorf("panic: %v", rec)
+		}
+	}()
+
+	return r.Run(ctx)
+}
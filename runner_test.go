@@ -0,0 +1,148 @@
+package di_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+// flakyWorker fails the first failures runs, then blocks until ctx is done.
+type flakyWorker struct {
+	runs     atomic.Int32
+	failures int32
+	err      error
+	panics   bool
+}
+
+func (w *flakyWorker) Run(ctx context.Context) error {
+	if w.runs.Add(1) <= w.failures {
+		if w.panics {
+			panic("boom")
+		}
+
+		return w.err
+	}
+
+	<-ctx.Done()
+
+	return ctx.Err()
+}
+
+// doneWorker returns nil right away, as if its loop ended on its own.
+type doneWorker struct {
+	runs atomic.Int32
+}
+
+func (w *doneWorker) Run(context.Context) error {
+	w.runs.Add(1)
+
+	return nil
+}
+
+type blockingWorker struct {
+	canceled atomic.Bool
+}
+
+func (w *blockingWorker) Run(ctx context.Context) error {
+	<-ctx.Done()
+	w.canceled.Store(true)
+
+	return nil
+}
+
+func TestApp_RunnerFailure(t *testing.T) {
+	errWorker := errors.New("worker failed")
+	log := &lifecycleLog{}
+
+	c := di.New()
+	c.Provide(func() *flakyWorker { return &flakyWorker{failures: 1, err: errWorker} })
+	c.Provide(func() *blockingWorker { return &blockingWorker{} })
+	c.Provide(func() *QueueService { return &QueueService{&orderedService{name: "queue", log: log}} })
+
+	worker := di.MustResolve[*flakyWorker](c)
+	blocking := di.MustResolve[*blockingWorker](c)
+	di.MustResolve[*QueueService](c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	err := di.NewApp(c).Run(ctx)
+	require.ErrorIs(t, err, errWorker)
+
+	var svcErr *di.ServiceError
+	require.ErrorAs(t, err, &svcErr)
+	require.Equal(t, "run", svcErr.Op)
+	require.NoError(t, ctx.Err())
+
+	require.EqualValues(t, 1, worker.runs.Load())
+	require.True(t, blocking.canceled.Load())
+	require.Equal(t, []string{"start queue", "started queue", "stop queue"}, log.get())
+}
+
+func TestApp_RunnerPanic(t *testing.T) {
+	c := di.New()
+	c.Provide(func() *flakyWorker { return &flakyWorker{failures: 1, panics: true} })
+	di.MustResolve[*flakyWorker](c)
+
+	err := di.NewApp(c).Run(context.Background())
+	require.ErrorContains(t, err, "panic: boom")
+}
+
+func TestApp_RunnerRestart(t *testing.T) {
+	errWorker := errors.New("worker failed")
+
+	t.Run("restarted until healthy", func(t *testing.T) {
+		c := di.New()
+		c.Provide(func() *flakyWorker { return &flakyWorker{failures: 2, err: errWorker} }).
+			Restart(di.RestartOnFailure(-1, time.Millisecond, 5*time.Millisecond))
+
+		worker := di.MustResolve[*flakyWorker](c)
+
+		ctx, cancel := context.WithTimeout(context.Background(), 200*time.Millisecond)
+		defer cancel()
+
+		require.NoError(t, di.NewApp(c).Run(ctx))
+		require.EqualValues(t, 3, worker.runs.Load())
+	})
+
+	t.Run("restarts exhausted", func(t *testing.T) {
+		c := di.New()
+		c.Provide(func() *flakyWorker { return &flakyWorker{failures: 5, err: errWorker} }).
+			Restart(di.RestartOnFailure(2, time.Millisecond, 0))
+
+		worker := di.MustResolve[*flakyWorker](c)
+
+		err := di.NewApp(c).Run(context.Background())
+		require.ErrorIs(t, err, errWorker)
+		require.EqualValues(t, 3, worker.runs.Load())
+	})
+}
+
+func TestApp_RunnerReturnsEarly(t *testing.T) {
+	c := di.New()
+	c.Provide(func() *doneWorker { return &doneWorker{} }).
+		Restart(di.RestartOnFailure(2, 0, 0))
+	c.Provide(func() *blockingWorker { return &blockingWorker{} })
+
+	worker := di.MustResolve[*doneWorker](c)
+	blocking := di.MustResolve[*blockingWorker](c)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	started := time.Now()
+	err := di.NewApp(c).Run(ctx)
+	require.ErrorIs(t, err, di.ErrRunnerReturned)
+	require.NoError(t, ctx.Err())
+	require.EqualValues(t, 3, worker.runs.Load())
+	require.True(t, blocking.canceled.Load())
+
+	// A zero backoff is raised to the minimum: 10ms, then 20ms.
+	require.GreaterOrEqual(t, time.Since(started), 30*time.Millisecond)
+}
@@ -0,0 +1,143 @@
+package di
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"slices"
+	"time"
+)
+
+// Reloader is implemented by components that can reload their configuration,
+// e.g. on SIGHUP.
+type Reloader interface {
+	Reload(ctx context.Context) error
+}
+
+// WithSignals sets the signals that make RunWithSignals shut down. The default is SIGINT and SIGTERM.
+func WithSignals(signals ...os.Signal) AppOpt {
+	return func(app *App) {
+		app.signals = signals
+	}
+}
+
+// WithReloadSignals sets the signals that make RunWithSignals call Reload. The default is SIGHUP,
+// except under js/wasm where there is none.
+func WithReloadSignals(signals ...os.Signal) AppOpt {
+	return func(app *App) {
+		app.reloadSignals = signals
+	}
+}
+
+// WithDrainDelay makes RunWithSignals wait before stopping the application after a shutdown
+// signal, so load balancers can deregister it. Readiness reports down during the delay.
+func WithDrainDelay(delay time.Duration) AppOpt {
+	return func(app *App) {
+		app.drainDelay = delay
+	}
+}
+
+// RunWithSignals is Run until one of the shutdown signals is received. A second shutdown
+// signal exits the process immediately with status 1. Reload signals call Reload.
+func (app *App) RunWithSignals(ctx context.Context) error {
+	sigCh := make(chan os.Signal, 1)
+
+	// Notify without signals would relay all of them.
+	if signals := slices.Concat(app.signals, app.reloadSignals); len(signals) > 0 {
+		signal.Notify(sigCh, signals...)
+		defer signal.Stop(sigCh)
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	done := make(chan struct{})
+	defer close(done)
+
+	go app.handleSignals(ctx, cancel, sigCh, done)
+
+	return app.Run(ctx)
+}
+
+// signalCause is the cancellation cause of the Run context after a shutdown signal.
+type signalCause struct {
+	signal os.Signal
+}
+
+func (c signalCause) Error() string {
+	return fmt.Sprintf("received signal %v", c.signal)
+}
+
+// handleSignals reacts to signals until done is closed when Run has returned. It keeps
+// listening after cancelling ctx, so a second shutdown signal can interrupt a slow stop.
+func (app *App) handleSignals(ctx context.Context, cancel context.CancelCauseFunc, sigCh <-chan os.Signal, done <-chan struct{}) {
+	var (
+		drain    <-chan time.Time
+		shutdown os.Signal
+	)
+
+	for {
+		select {
+		case <-done:
+			return
+		case <-drain:
+			drain = nil
+			cancel(signalCause{signal: shutdown})
+		case sig := <-sigCh:
+			switch {
+			case shutdown != nil && slices.Contains(app.signals, sig):
+				app.emit(SignalReceived{Signal: sig, Action: "exit"})
+				app.exit(1)
+
+				return
+			case slices.Contains(app.signals, sig):
+				shutdown = sig
+
+				if app.drainDelay <= 0 {
+					app.emit(SignalReceived{Signal: sig, Action: "shutdown"})
+					cancel(signalCause{signal: sig})
+
+					continue
+				}
+
+				app.emit(SignalReceived{Signal: sig, Action: "drain"})
+				app.state.Store(int32(StateStopping))
+
+				timer := time.NewTimer(app.drainDelay)
+				defer timer.Stop()
+
+				drain = timer.C
+			case shutdown == nil && slices.Contains(app.reloadSignals, sig):
+				app.emit(SignalReceived{Signal: sig, Action: "reload"})
+				_ = app.Reload(ctx)
+			}
+		}
+	}
+}
+
+// Reload calls Reload on every Reloader instance in construction order and joins the errors.
+func (app *App) Reload(ctx context.Context) error {
+	app.container.mu.Lock()
+	entries := slices.Clone(app.container.instancesList)
+	app.container.mu.Unlock()
+
+	var errs []error
+	for _, entry := range entries {
+		reloader, ok := entry.value.(Reloader)
+		if !ok {
+			continue
+		}
+
+		frame := entry.provider.frame()
+		err := reloader.Reload(ctx)
+		app.emit(ServiceReloaded{Service: frame, Err: err})
+
+		if err != nil {
+			errs = append(errs, &ServiceError{Op: "reload", Service: frame, Err: err})
+		}
+	}
+
+	return errors.Join(errs...)
+}
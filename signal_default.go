@@ -0,0 +1,13 @@
+//go:build !js
+
+package di
+
+import (
+	"os"
+	"syscall"
+)
+
+var (
+	defaultSignals       = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	defaultReloadSignals = []os.Signal{syscall.SIGHUP}
+)
@@ -0,0 +1,12 @@
+package di
+
+import (
+	"os"
+	"syscall"
+)
+
+// There is no SIGHUP under js/wasm, so there is no default reload signal.
+var (
+	defaultSignals       = []os.Signal{syscall.SIGINT, syscall.SIGTERM}
+	defaultReloadSignals []os.Signal
+)
@@ -0,0 +1,153 @@
+//go:build unix
+
+package di_test
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+type reloadableCache struct {
+	reloads atomic.Int32
+}
+
+func (c *reloadableCache) Reload(context.Context) error {
+	if c.reloads.Add(1) > 1 {
+		return errors.New("reload failed")
+	}
+
+	return nil
+}
+
+func TestApp_RunWithSignals(t *testing.T) {
+	c := di.New()
+	c.Provide(func() *reloadableCache { return &reloadableCache{} })
+	cache := di.MustResolve[*reloadableCache](c)
+
+	const drain = 100 * time.Millisecond
+	rec := &eventRecorder{}
+	app := di.NewApp(c,
+		di.WithObserver(rec),
+		di.WithSignals(syscall.SIGUSR1),
+		di.WithReloadSignals(syscall.SIGUSR2),
+		di.WithDrainDelay(drain),
+	)
+
+	done := make(chan error, 1)
+	go func() { done <- app.RunWithSignals(context.Background()) }()
+
+	require.Eventually(t, func() bool { return app.State() == di.StateRunning }, time.Second, time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR2))
+	require.Eventually(t, func() bool { return cache.reloads.Load() == 1 }, time.Second, time.Millisecond)
+
+	signaled := time.Now()
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	require.Eventually(t, func() bool { return app.State() == di.StateStopping }, time.Second, time.Millisecond)
+	require.Equal(t, di.StatusDown, app.Ready(context.Background()).Status)
+
+	select {
+	case err := <-done:
+		require.NoError(t, err)
+		require.GreaterOrEqual(t, time.Since(signaled), drain)
+	case <-time.After(time.Second):
+		t.Fatal("app did not stop")
+	}
+
+	require.Equal(t, di.StateStopped, app.State())
+
+	stopping := rec.find("di.AppStopping").(di.AppStopping)
+	require.Equal(t, di.ShutdownSignal, stopping.Reason)
+	require.Equal(t, syscall.SIGUSR1, stopping.Signal)
+}
+
+func TestApp_RunWithoutSignals(t *testing.T) {
+	c := di.New()
+	c.Provide(func() *reloadableCache { return &reloadableCache{} })
+	cache := di.MustResolve[*reloadableCache](c)
+
+	app := di.NewApp(c, di.WithSignals(), di.WithReloadSignals())
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() { done <- app.RunWithSignals(ctx) }()
+
+	require.Eventually(t, func() bool { return app.State() == di.StateRunning }, time.Second, time.Millisecond)
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGURG))
+	time.Sleep(50 * time.Millisecond)
+
+	cancel()
+	require.NoError(t, <-done)
+	require.Zero(t, cache.reloads.Load())
+}
+
+func TestApp_Reload(t *testing.T) {
+	c := di.New()
+	c.Provide(func() *reloadableCache { return &reloadableCache{} })
+	di.MustResolve[*reloadableCache](c)
+
+	app := di.NewApp(c)
+	require.NoError(t, app.Reload(context.Background()))
+
+	err := app.Reload(context.Background())
+
+	var svcErr *di.ServiceError
+	require.ErrorAs(t, err, &svcErr)
+	require.Equal(t, "reload", svcErr.Op)
+}
+
+// slowStopper blocks in Stop until released.
+type slowStopper struct {
+	stopping chan struct{}
+	release  chan struct{}
+}
+
+func (s *slowStopper) Start(context.Context) error { return nil }
+
+func (s *slowStopper) Stop(context.Context) error {
+	close(s.stopping)
+	<-s.release
+
+	return nil
+}
+
+func TestApp_RunWithSignalsForcedExit(t *testing.T) {
+	stopper := &slowStopper{stopping: make(chan struct{}), release: make(chan struct{})}
+
+	c := di.New()
+	c.Provide(func() *slowStopper { return stopper })
+	di.MustResolve[*slowStopper](c)
+
+	app := di.NewApp(c, di.WithSignals(syscall.SIGUSR1))
+
+	exited := make(chan int, 1)
+	di.SetExit(app, func(code int) { exited <- code })
+
+	done := make(chan error, 1)
+	go func() { done <- app.RunWithSignals(context.Background()) }()
+
+	require.Eventually(t, func() bool { return app.State() == di.StateRunning }, time.Second, time.Millisecond)
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+	<-stopper.stopping
+
+	require.NoError(t, syscall.Kill(syscall.Getpid(), syscall.SIGUSR1))
+
+	select {
+	case code := <-exited:
+		require.Equal(t, 1, code)
+	case <-time.After(time.Second):
+		t.Fatal("second signal did not exit")
+	}
+
+	close(stopper.release)
+	require.NoError(t, <-done)
+}
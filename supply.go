@@ -0,0 +1,57 @@
+package di
+
+import (
+	"fmt"
+	"reflect"
+	"runtime"
+)
+
+// Supply registers already built values, e.g. a *Config parsed in main. Each value becomes a
+// singleton provider of its dynamic type, is immediately part of the container instances and
+// takes part in the App lifecycle if it implements Servicer or has hooks. It is never closed
+// or shut down automatically. Decorators of the value's own type are not applied. The
+// returned provider belongs to the last value, so options such as As or Named are meant to be
+// chained when supplying a single value.
+func (c *Container) Supply(values ...any) *Provider {
+	if len(values) == 0 {
+		panic("nothing to supply")
+	}
+
+	_, file, line, _ := runtime.Caller(1)
+
+	var prvdr *Provider
+	for _, value := range values {
+		if value == nil {
+			panic("cannot supply nil")
+		}
+
+		prvdr = newSuppliedProvider(value, file, line)
+		c.register(prvdr)
+
+		inst := reflect.ValueOf(value)
+
+		c.mu.Lock()
+		c.instances[instanceKey{provider: prvdr, typ: prvdr.returnType}] = inst
+		c.instancesList = append(c.instancesList, instanceEntry{provider: prvdr, value: value})
+		c.mu.Unlock()
+	}
+
+	return prvdr
+}
+
+func newSuppliedProvider(value any, file string, line int) *Provider {
+	typ := reflect.TypeOf(value)
+
+	return &Provider{
+		name:       fmt.Sprintf("Supply(%v)", typ),
+		file:       file,
+		line:       line,
+		returnType: typ,
+		initFunc: func([]reflect.Value) (any, error) {
+			return value, nil
+		},
+		supplied: true,
+		args:     make(map[reflect.Type]reflect.Value),
+		bindings: make(map[int]binding),
+	}
+}
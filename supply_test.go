@@ -0,0 +1,64 @@
+package di_test
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+func TestContainer_Supply(t *testing.T) {
+	cfg := &DBConfig{DSN: "dsn"}
+	params := &MyServiceParams{ParamInt: 1}
+
+	c := di.New()
+	c.Supply(cfg, params)
+	c.Provide(NewDBClientFromConfig)
+
+	db, err := di.Resolve[*DBClientImpl](c)
+	require.NoError(t, err)
+	require.Equal(t, "dsn", db.data)
+	require.Same(t, params, di.MustResolve[*MyServiceParams](c))
+
+	require.Panics(t, func() { c.Supply(nil) })
+}
+
+func TestContainer_SupplyAs(t *testing.T) {
+	c := di.New()
+	c.Supply(NewDBClient("supplied")).As(new(DBClient))
+	c.Provide(NewMockDBClient)
+	c.Provide(NewRepo)
+
+	repo := di.MustResolve[Repo](c)
+	data, err := repo.Find()
+	require.NoError(t, err)
+	require.Equal(t, "supplied", data)
+}
+
+func TestContainer_SupplyGraph(t *testing.T) {
+	c := di.New()
+	c.Supply(&DBConfig{})
+
+	var sb strings.Builder
+	require.NoError(t, c.Graph().WriteDOT(&sb))
+	require.Contains(t, sb.String(), `p0 [label="supplied\n*di_test.DBConfig", style="rounded"];`)
+}
+
+func TestContainer_SupplyLifecycle(t *testing.T) {
+	srv := &MockAppService1{}
+	srv.On("Start", mock.Anything).Return(nil)
+	srv.On("Stop", mock.Anything).Return(nil)
+
+	c := di.New()
+	c.Supply(srv)
+
+	app := di.NewApp(c)
+	require.NoError(t, app.Start(context.Background()))
+	require.NoError(t, app.Stop(context.Background()))
+
+	srv.AssertExpectations(t)
+}
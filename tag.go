@@ -0,0 +1,59 @@
+package di
+
+import (
+	"fmt"
+	"strings"
+)
+
+const tagName = "di"
+
+// fieldTag is a parsed `di:"..."` struct tag.
+// Options are comma separated, e.g. `di:"name=replica,optional"` or `di:"group=routes"`.
+// `di:"-"` excludes a field from injection.
+type fieldTag struct {
+	skip     bool
+	name     string
+	group    string
+	optional bool
+}
+
+func parseTag(tag string) (fieldTag, error) {
+	var ft fieldTag
+	if tag == "" {
+		return ft, nil
+	}
+
+	if tag == "-" {
+		ft.skip = true
+
+		return ft, nil
+	}
+
+	for _, opt := range strings.Split(tag, ",") {
+		key, value, _ := strings.Cut(strings.TrimSpace(opt), "=")
+		switch key {
+		case "name":
+			if value == "" {
+				return ft, fmt.Errorf("empty name in tag %q", tag)
+			}
+
+			ft.name = value
+		case "group":
+			if value == "" {
+				return ft, fmt.Errorf("empty group in tag %q", tag)
+			}
+
+			ft.group = value
+		case "optional":
+			ft.optional = true
+		default:
+			return ft, fmt.Errorf("unknown option %q in tag %q", key, tag)
+		}
+	}
+
+	if ft.group != "" && (ft.name != "" || ft.optional) {
+		return ft, fmt.Errorf("group cannot be combined with other options in tag %q", tag)
+	}
+
+	return ft, nil
+}
@@ -0,0 +1,253 @@
+package di
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"slices"
+)
+
+// Validate checks the dependency graph without constructing anything. It reports
+// duplicate providers, missing and ambiguous dependencies, circular dependencies and
+// singletons depending on scoped providers. All problems are returned at once,
+// joined with errors.Join.
+func (c *Container) Validate() error {
+	root := c.root()
+	root.mu.Lock()
+	defer root.mu.Unlock()
+
+	v := &validator{
+		providers: root.providers,
+		deps:      make(map[*Provider][]*Provider),
+		visited:   make(map[*Provider]bool),
+		scoped:    make(map[*Provider]bool),
+	}
+
+	v.checkDuplicates()
+	for _, prov := range v.providers {
+		v.deps[prov] = v.dependencies(prov)
+	}
+
+	for _, d := range root.decorators {
+		v.dependencies(d)
+	}
+
+	for _, prov := range v.providers {
+		v.checkCycles(prov, nil)
+	}
+
+	for _, prov := range v.providers {
+		v.checkLifetime(prov)
+	}
+
+	return errors.Join(v.errs...)
+}
+
+type validator struct {
+	providers []*Provider
+	deps      map[*Provider][]*Provider
+	visited   map[*Provider]bool
+	scoped    map[*Provider]bool
+	errs      []error
+}
+
+func (v *validator) checkDuplicates() {
+	seen := make(map[string]*Provider)
+	for _, prov := range v.providers {
+		key := registrationKey(prov)
+		if first, ok := seen[key]; ok {
+			v.errs = append(v.errs, fmt.Errorf("duplicate provider %s: %s", key, providerNames([]*Provider{first, prov})))
+
+			continue
+		}
+
+		seen[key] = prov
+	}
+}
+
+func (v *validator) dependencies(p *Provider) []*Provider {
+	var deps []*Provider
+	for _, dep := range staticDependencies(v.providers, p) {
+		if dep.err != nil {
+			v.errs = append(v.errs, dep.err)
+
+			continue
+		}
+
+		// Deferred dependencies are resolved after construction and cannot close a cycle.
+		if !dep.deferred {
+			deps = append(deps, dep.providers...)
+		}
+	}
+
+	return deps
+}
+
+// staticDependency describes how a constructor parameter is satisfied, without building anything.
+type staticDependency struct {
+	paramType reflect.Type
+	arg       bool
+	optional  bool
+	deferred  bool
+	providers []*Provider
+	err       error
+}
+
+func staticDependencies(providers []*Provider, p *Provider) []staticDependency {
+	providers = visibleTo(providers, p)
+	deps := make([]staticDependency, 0, len(p.paramTypes))
+	for i, pt := range p.paramTypes {
+		if i == 0 && p.decorator {
+			continue
+		}
+
+		if b, ok := p.bindings[i]; ok {
+			switch {
+			case b.value.IsValid():
+				deps = append(deps, staticDependency{paramType: pt, arg: true})
+			case b.fn != nil:
+				deps = append(deps, staticDependencies(providers, b.fn)...)
+			case b.provider != nil:
+				deps = append(deps, staticDependency{paramType: pt, providers: []*Provider{b.provider}})
+			default:
+				deps = append(deps, staticDependencyOf(providers, p, pt, fieldTag{name: b.name}))
+			}
+
+			continue
+		}
+
+		if _, ok := p.args[pt]; ok {
+			deps = append(deps, staticDependency{paramType: pt, arg: true})
+
+			continue
+		}
+
+		if embeds(pt, inType) {
+			fields, _ := markedFields(pt, inType)
+			for _, field := range fields {
+				deps = append(deps, staticDependencyOf(providers, p, field.typ, field.tag))
+			}
+
+			continue
+		}
+
+		deps = append(deps, staticDependencyOf(providers, p, pt, fieldTag{}))
+	}
+
+	return deps
+}
+
+func staticDependencyOf(providers []*Provider, p *Provider, t reflect.Type, tag fieldTag) staticDependency {
+	dep := staticDependency{paramType: t, optional: tag.optional}
+
+	if tag.group != "" {
+		for _, prov := range providers {
+			if prov.group == tag.group && providesType(prov, t.Elem()) {
+				dep.providers = append(dep.providers, prov)
+			}
+		}
+
+		return dep
+	}
+
+	lookupType := t
+	if elem, ok := optionalElem(t); ok {
+		lookupType = elem
+		dep.optional = true
+	}
+
+	if elem, ok := lazyElem(t); ok {
+		lookupType = elem
+		dep.deferred = true
+	}
+
+	if elem, ok := factoryElem(t); ok {
+		if found, err := findProvider(providers, t, tag.name); found == nil && err == nil {
+			lookupType = elem
+			dep.deferred = true
+		}
+	}
+
+	found, err := findProvider(providers, lookupType, tag.name)
+	switch {
+	case err != nil:
+		dep.err = wrapChain([]*Provider{p}, err)
+	case found != nil:
+		dep.providers = []*Provider{found}
+	case tag.name == "" && lookupType.Kind() == reflect.Slice && lookupType.Elem().Kind() == reflect.Interface:
+		for _, prov := range providers {
+			if prov != p && providesType(prov, lookupType.Elem()) {
+				dep.providers = append(dep.providers, prov)
+			}
+		}
+	case dep.optional:
+		// An absent optional dependency resolves to the zero value.
+	default:
+		dep.err = &MissingDependencyError{Type: lookupType, Name: tag.name, Chain: newChain([]*Provider{p})}
+	}
+
+	return dep
+}
+
+// checkCycles walks the graph depth-first and reports every cycle it closes.
+func (v *validator) checkCycles(p *Provider, path []*Provider) {
+	for i, prov := range path {
+		if prov == p {
+			v.errs = append(v.errs, &CycleError{Chain: newChain(append(slices.Clone(path[i:]), p))})
+
+			return
+		}
+	}
+
+	if v.visited[p] {
+		return
+	}
+
+	path = append(path, p)
+	for _, dep := range v.deps[p] {
+		v.checkCycles(dep, path)
+	}
+
+	v.visited[p] = true
+}
+
+func (v *validator) checkLifetime(p *Provider) {
+	if p.lifetime != Singleton {
+		return
+	}
+
+	for _, dep := range v.deps[p] {
+		if v.needsScope(dep, make(map[*Provider]bool)) {
+			v.errs = append(v.errs, fmt.Errorf("singleton %s cannot depend on scoped provider %s", p.name, dep.name))
+		}
+	}
+}
+
+// needsScope reports whether building p requires a scope, i.e. p is scoped or is a
+// transient that depends on a scoped provider.
+func (v *validator) needsScope(p *Provider, seen map[*Provider]bool) bool {
+	if needs, ok := v.scoped[p]; ok {
+		return needs
+	}
+
+	if seen[p] {
+		return false
+	}
+
+	seen[p] = true
+
+	needs := p.lifetime == Scoped
+	if p.lifetime == Transient {
+		for _, dep := range v.deps[p] {
+			if v.needsScope(dep, seen) {
+				needs = true
+
+				break
+			}
+		}
+	}
+
+	v.scoped[p] = needs
+
+	return needs
+}
@@ -0,0 +1,58 @@
+package di_test
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+
+	"github.com/rom8726/di"
+)
+
+func TestContainer_Validate(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("data")
+	c.Provide(NewRepo)
+	c.Provide(NewMyService).Arg(&MyServiceParams{})
+	c.Provide(NewMyService2).Args(2, true)
+	c.Provide(NewRootService)
+
+	require.NoError(t, c.Validate())
+}
+
+func TestContainer_ValidateReportsAllProblems(t *testing.T) {
+	constructed := false
+
+	c := di.New()
+	c.Provide(func() *DBClientImpl {
+		constructed = true
+
+		return NewDBClient("data")
+	})
+	c.Provide(NewMockDBClient)
+	c.Provide(NewRepo)
+	c.Provide(NewMyService)
+	c.Provide(newDep1)
+	c.Provide(newDep2)
+	c.Provide(func() *RequestCtx { return &RequestCtx{} }).Scoped()
+	c.Provide(NewHandler)
+
+	err := c.Validate()
+	require.Error(t, err)
+	require.False(t, constructed)
+
+	msg := err.Error()
+	require.Contains(t, msg, "ambiguous providers for di_test.DBClient")
+	require.Contains(t, msg, "di_test.NewMyService (missing *di_test.MyServiceParams)")
+	require.Contains(t, msg, "circular dependency detected")
+	require.Contains(t, msg, "cannot depend on scoped provider")
+}
+
+func TestContainer_ValidateDuplicates(t *testing.T) {
+	c := di.New()
+	c.Provide(NewDBClient).Arg("a")
+	c.Provide(NewDBClient).Arg("b")
+	c.Provide(NewDBClient).Arg("c").Named("c")
+
+	err := c.Validate()
+	require.ErrorContains(t, err, "duplicate provider *di_test.DBClientImpl")
+}